@@ -0,0 +1,168 @@
+package drive
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// ClientAssertionType is the client_assertion_type of a JWT client assertion
+const ClientAssertionType string = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// NewGraphClientWithCertificate creates a new Client instance which authenticates the application with a
+// certificate instead of a client secret and grab's a token. The private key has to be an RSA key.
+// See https://docs.microsoft.com/en-us/azure/active-directory/develop/active-directory-certificate-credentials
+//
+// Returns an error if the token can not be initialized.
+func NewGraphClientWithCertificate(tenantID, applicationID string, cert *x509.Certificate, key crypto.PrivateKey, opts ...Option) (*Client, error) {
+	return NewGraphClientWithCertificateContext(context.Background(), tenantID, applicationID, cert, key, opts...)
+}
+
+// NewGraphClientWithCertificateContext is like NewGraphClientWithCertificate but uses ctx to grab the token.
+func NewGraphClientWithCertificateContext(ctx context.Context, tenantID, applicationID string, cert *x509.Certificate, key crypto.PrivateKey, opts ...Option) (*Client, error) {
+	g := Client{TenantID: tenantID, ApplicationID: applicationID}
+	for _, opt := range opts {
+		opt(&g)
+	}
+	g.src = g.wrapSource(&ClientCredentials{
+		TenantID:      tenantID,
+		ApplicationID: applicationID,
+		Certificate:   cert,
+		PrivateKey:    key,
+		V1Endpoint:    g.v1Endpoint,
+	})
+	return &g, g.refreshToken(ctx)
+}
+
+// ParseCertificatePEM parses a PEM encoded certificate and its unencrypted private key (PKCS#1 or PKCS#8).
+// keyPEM may be nil if the key is contained in certPEM.
+func ParseCertificatePEM(certPEM, keyPEM []byte) (*x509.Certificate, crypto.PrivateKey, error) {
+	var cert *x509.Certificate
+	var key crypto.PrivateKey
+	for _, data := range [][]byte{certPEM, keyPEM} {
+		for {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				break
+			}
+			switch block.Type {
+			case "CERTIFICATE":
+				if cert != nil { // the first certificate is the one of the application, skip the chain
+					continue
+				}
+				c, err := x509.ParseCertificate(block.Bytes)
+				if err != nil {
+					return nil, nil, fmt.Errorf("unable to parse certificate: %v", err)
+				}
+				cert = c
+			case "RSA PRIVATE KEY":
+				k, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+				if err != nil {
+					return nil, nil, fmt.Errorf("unable to parse private key: %v", err)
+				}
+				key = k
+			case "PRIVATE KEY":
+				k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+				if err != nil {
+					return nil, nil, fmt.Errorf("unable to parse private key: %v", err)
+				}
+				key = k
+			}
+		}
+	}
+	if cert == nil {
+		return nil, nil, fmt.Errorf("no certificate found")
+	}
+	if key == nil {
+		return nil, nil, fmt.Errorf("no private key found")
+	}
+	if err := checkKeyPair(cert, key); err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// ParseCertificatePKCS12 parses a PKCS#12 (.pfx, .p12) file containing a certificate and its private key.
+// The file may contain the chain of the certificate, which is skipped, and may be encrypted with the legacy
+// algorithms as well as with AES (PBES2), the default of OpenSSL 3 and current Azure exports.
+func ParseCertificatePKCS12(data []byte, password string) (*x509.Certificate, crypto.PrivateKey, error) {
+	key, cert, _, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to decode PKCS#12 data: %v", err)
+	}
+	if err := checkKeyPair(cert, key); err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// checkKeyPair returns an error unless key is the private key of cert, otherwise a wrong key
+// would only be noticed as failing login
+func checkKeyPair(cert *x509.Certificate, key crypto.PrivateKey) error {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("private key of type %T is not supported", key)
+	}
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(cert.PublicKey) {
+		return fmt.Errorf("private key does not belong to the certificate %v", cert.Subject)
+	}
+	return nil
+}
+
+// clientAssertion returns a JWT for audience signed with key, identifying the application by the thumbprint of cert.
+// See https://docs.microsoft.com/en-us/azure/active-directory/develop/active-directory-certificate-credentials#assertion-format
+func clientAssertion(applicationID, audience string, cert *x509.Certificate, key crypto.PrivateKey) (string, error) {
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("private key of type %T is not supported, an RSA key is required", key)
+	}
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+	thumbprint := sha1.Sum(cert.Raw)
+	now := time.Now()
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"x5t": base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": audience,
+		"iss": applicationID,
+		"sub": applicationID,
+		"jti": hex.EncodeToString(jti),
+		"nbf": now.Unix(),
+		"iat": now.Unix(),
+		"exp": now.Add(10 * time.Minute).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("unable to sign client assertion: %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
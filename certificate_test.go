@@ -0,0 +1,157 @@
+package drive_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	drive "github.com/iochen/msgraph-drive"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func TestNewGraphClientWithCertificate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "drive-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	cert, privKey, err := drive.ParseCertificatePEM(append(certPEM, keyPEM...), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(otherKey)})
+	if _, _, err := drive.ParseCertificatePEM(certPEM, otherPEM); err == nil {
+		t.Error("mismatching private key did not fail")
+	}
+
+	var malformed int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_secret") != "" || r.FormValue("client_assertion_type") != drive.ClientAssertionType {
+			t.Errorf("unexpected form %v", r.Form)
+		}
+		parts := strings.Split(r.FormValue("client_assertion"), ".")
+		if len(parts) != 3 {
+			// t.Fatal must only be called by the test goroutine
+			t.Errorf("malformed assertion %q", r.FormValue("client_assertion"))
+			atomic.StoreInt32(&malformed, 1)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+			t.Errorf("invalid signature: %v", err)
+		}
+		claims := map[string]interface{}{}
+		raw, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		json.Unmarshal(raw, &claims)
+		if claims["iss"] != "application" || claims["aud"] != "http://"+r.Host+r.URL.Path {
+			t.Errorf("unexpected claims %v", claims)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token_type":   "Bearer",
+			"expires_in":   3599,
+			"access_token": "cert-token",
+		})
+	}))
+	defer srv.Close()
+
+	_, err = drive.NewGraphClientWithCertificate("tenant", "application", cert, privKey, drive.WithLoginBaseURL(srv.URL))
+	if atomic.LoadInt32(&malformed) != 0 {
+		t.FailNow()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newCertificate creates a certificate for key signed by parent, a self-signed one if parent is nil
+func newCertificate(t *testing.T, name string, key *rsa.PrivateKey, parent *x509.Certificate, parentKey *rsa.PrivateKey) *x509.Certificate {
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if parent == nil {
+		parent, parentKey = tpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestParseCertificatePKCS12(t *testing.T) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := newCertificate(t, "drive-test-ca", caKey, nil, nil)
+	cert := newCertificate(t, "drive-test", key, ca, caKey)
+
+	// AES encrypted along with the chain, as exported by OpenSSL 3
+	data, err := pkcs12.Modern.Encode(key, cert, []*x509.Certificate{ca}, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotCert, gotKey, err := drive.ParseCertificatePKCS12(data, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotCert.Equal(cert) || !key.Equal(gotKey) {
+		t.Errorf("unexpected certificate %v", gotCert.Subject)
+	}
+	if _, _, err := drive.ParseCertificatePKCS12(data, "wrong"); err == nil {
+		t.Error("wrong password did not fail")
+	}
+
+	// the key of the CA does not belong to the certificate
+	data, err = pkcs12.Modern.Encode(caKey, cert, nil, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := drive.ParseCertificatePKCS12(data, "secret"); err == nil {
+		t.Error("mismatching private key did not fail")
+	}
+}
@@ -0,0 +1,103 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChildIterator iterates over the children of a folder. Pages are fetched
+// lazily, hence only a single page is held in memory at any time.
+//
+//	it := drv.Children("/folder")
+//	for it.Next() {
+//		item := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//		// handle error
+//	}
+type ChildIterator struct {
+	ctx    context.Context
+	drv    *Drive
+	source string // API-call of the first page, empty once it has been fetched
+	next   string // @odata.nextLink of the current page, empty on the last page
+	items  []*Item
+	idx    int
+	err    error
+}
+
+// Children returns an iterator over the children of the folder at path.
+// No API-call is performed until Next is called.
+func (drv *Drive) Children(path string) *ChildIterator {
+	return drv.ChildrenContext(context.Background(), path)
+}
+
+// ChildrenContext is like Children but performs all API-calls of the iterator with ctx.
+func (drv *Drive) ChildrenContext(ctx context.Context, path string) *ChildIterator {
+	return &ChildIterator{
+		ctx:    ctx,
+		drv:    drv,
+		source: drv.childrenSource(path),
+		idx:    -1,
+	}
+}
+
+// ChildrenByID is like Children but addresses the folder by its ID.
+func (drv *Drive) ChildrenByID(id string) *ChildIterator {
+	return drv.ChildrenByIDContext(context.Background(), id)
+}
+
+// ChildrenByIDContext is like ChildrenByID but performs all API-calls of the iterator with ctx.
+func (drv *Drive) ChildrenByIDContext(ctx context.Context, id string) *ChildIterator {
+	it := &ChildIterator{
+		ctx:    ctx,
+		drv:    drv,
+		source: drv.idSource(id) + "/children",
+		idx:    -1,
+	}
+	if id == "" {
+		it.err = fmt.Errorf("item ID is empty")
+	}
+	return it
+}
+
+// Next advances the iterator to the next child and fetches the next page if
+// required. It returns false when there are no more children or an error
+// occurred, Err tells both cases apart.
+func (it *ChildIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.idx++
+	for it.idx >= len(it.items) { // loop as a page might be empty
+		if it.source == "" && it.next == "" {
+			it.items = nil
+			return false
+		}
+		marsh := &page{}
+		if it.source != "" {
+			it.err = it.drv.Client.makeGETAPICall(it.ctx, it.source, nil, marsh)
+			it.source = ""
+		} else {
+			it.err = it.drv.Client.makeGETURLCall(it.ctx, it.next, marsh)
+		}
+		if it.err != nil {
+			it.items = nil
+			return false
+		}
+		it.items, it.next, it.idx = marsh.Items, marsh.NextLink, 0
+	}
+	return true
+}
+
+// Item returns the current child. It must only be called after Next returned true.
+func (it *ChildIterator) Item() *Item {
+	if it.idx < 0 || it.idx >= len(it.items) {
+		return nil
+	}
+	return it.items[it.idx]
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *ChildIterator) Err() error {
+	return it.err
+}
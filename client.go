@@ -2,14 +2,16 @@ package drive
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
-	"time"
 )
 
 // Client represents a msgraph API connection instance.
@@ -17,13 +19,22 @@ import (
 // An instance can also be json-unmarshalled an will immediately be initialized, hence a Token will be
 // grabbed. If grabbing a token fails the JSON-Unmarshal returns an error.
 type Client struct {
-	sync.Mutex // lock it when performing an API-call to synchronize it
-
 	TenantID      string // See https://docs.microsoft.com/en-us/azure/azure-resource-manager/resource-group-create-service-principal-portal#get-tenant-id
 	ApplicationID string // See https://docs.microsoft.com/en-us/azure/azure-resource-manager/resource-group-create-service-principal-portal#get-application-id-and-authentication-key
 	ClientSecret  string // See https://docs.microsoft.com/en-us/azure/azure-resource-manager/resource-group-create-service-principal-portal#get-application-id-and-authentication-key
 
-	token Token // the current token to be used
+	mu  sync.Mutex  // protects src, API-calls themselves run concurrently
+	src TokenSource // supplies the tokens, see NewClient
+
+	httpClient   *http.Client // see WithHTTPClient, WithTransport and WithTimeout
+	loginBaseURL string       // see WithLoginBaseURL
+	baseURL      string       // see WithBaseURL
+	resource     string       // the resource tokens are acquired for, see WithCloud
+	apiVersion   string       // see WithAPIVersion
+	userAgent    string       // see WithUserAgent
+	retry        *RetryPolicy // see WithRetryPolicy
+	v1Endpoint   bool         // see WithV1Endpoint
+	cache        TokenCache   // see WithTokenCache
 }
 
 func (cli *Client) String() string {
@@ -32,121 +43,189 @@ func (cli *Client) String() string {
 		firstPart = cli.ClientSecret[0:3]
 		lastPart = cli.ClientSecret[len(cli.ClientSecret)-3:]
 	}
+	var token Token
+	if rs, ok := cli.source().(*refreshingSource); ok {
+		token = rs.current()
+	}
 	return fmt.Sprintf("Client(TenantID: %v, ApplicationID: %v, ClientSecret: %v...%v, Token validity: [%v - %v])",
-		cli.TenantID, cli.ApplicationID, firstPart, lastPart, cli.token.NotBefore, cli.token.ExpiresOn)
+		cli.TenantID, cli.ApplicationID, firstPart, lastPart, token.NotBefore, token.ExpiresOn)
 }
 
 // NewGraphClient creates a new Client instance with the given parameters and grab's a token.
+// Tokens are acquired with the client credentials flow, see ClientCredentials.
 //
 // Returns an error if the token can not be initialized. This method does not have to be used to create a new Client
-func NewGraphClient(tenantID, applicationID, clientSecret string) (*Client, error) {
+func NewGraphClient(tenantID, applicationID, clientSecret string, opts ...Option) (*Client, error) {
+	return NewGraphClientContext(context.Background(), tenantID, applicationID, clientSecret, opts...)
+}
+
+// NewGraphClientContext is like NewGraphClient but uses ctx to grab the token.
+func NewGraphClientContext(ctx context.Context, tenantID, applicationID, clientSecret string, opts ...Option) (*Client, error) {
 	g := Client{TenantID: tenantID, ApplicationID: applicationID, ClientSecret: clientSecret}
-	g.Lock()         // lock because we will refresh the token
-	defer g.Unlock() // unlock after token refresh
-	return &g, g.refreshToken()
+	for _, opt := range opts {
+		opt(&g)
+	}
+	return &g, g.refreshToken(ctx)
 }
 
-// refreshToken refreshes the current Token. Grab's a new one and saves it within the Client instance
-func (cli *Client) refreshToken() error {
-	if cli.TenantID == "" {
-		return fmt.Errorf("tenant ID is empty")
+// NewClient creates a new Client instance which gets its tokens from src and grab's a token.
+//
+// Returns an error if the token can not be initialized.
+func NewClient(src TokenSource, opts ...Option) (*Client, error) {
+	return NewClientContext(context.Background(), src, opts...)
+}
+
+// NewClientContext is like NewClient but uses ctx to grab the token.
+func NewClientContext(ctx context.Context, src TokenSource, opts ...Option) (*Client, error) {
+	g := Client{}
+	for _, opt := range opts {
+		opt(&g)
 	}
-	resource := fmt.Sprintf("/%v/oauth2/token", cli.TenantID)
-	data := url.Values{}
-	data.Add("grant_type", "client_credentials")
-	data.Add("client_id", cli.ApplicationID)
-	data.Add("client_secret", cli.ClientSecret)
-	data.Add("resource", BaseURL)
+	g.src = g.wrapSource(src)
+	return &g, g.refreshToken(ctx)
+}
 
-	u, err := url.ParseRequestURI(LoginBaseURL)
-	if err != nil {
-		return fmt.Errorf("unable to parse URI: %v", err)
+// source returns the TokenSource of the Client. A Client which has not been created with NewClient
+// acquires its tokens with the client credentials flow using TenantID, ApplicationID and ClientSecret.
+func (cli *Client) source() TokenSource {
+	cli.mu.Lock()
+	defer cli.mu.Unlock()
+	if cli.src == nil {
+		cli.src = cli.wrapSource(&ClientCredentials{
+			TenantID:      cli.TenantID,
+			ApplicationID: cli.ApplicationID,
+			ClientSecret:  cli.ClientSecret,
+			V1Endpoint:    cli.v1Endpoint,
+		})
 	}
+	return cli.src
+}
 
-	u.Path = resource
-	req, err := http.NewRequest("POST", u.String(), bytes.NewBufferString(data.Encode()))
+// wrapSource makes src reuse its token until it wants to be refreshed and, if the Client has
+// a TokenCache, read the token from and write it to the cache
+func (cli *Client) wrapSource(src TokenSource) TokenSource {
+	if cli.cache != nil {
+		src = CachedTokenSource(cli.cache, src)
+	}
+	return RefreshingTokenSource(src)
+}
 
+// accessToken returns the current token in Bearer format. The token is refreshed beforehand if required.
+func (cli *Client) accessToken(ctx context.Context) (string, error) {
+	token, err := cli.source().Token(withClient(ctx, cli))
 	if err != nil {
-		return fmt.Errorf("HTTP Request Error: %v", err)
+		return "", err
 	}
+	return token.GetAccessToken(), nil
+}
+
+// refreshToken makes sure the Client holds a valid token
+func (cli *Client) refreshToken(ctx context.Context) error {
+	_, err := cli.accessToken(ctx)
+	return err
+}
 
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Add("Content-Length", strconv.Itoa(len(data.Encode())))
+// makeGETAPICall performs an API-Call to the msgraph API
+func (cli *Client) makeGETAPICall(ctx context.Context, apicall string, getParams url.Values, v interface{}) error {
+	if getParams == nil { // initialize getParams if it's nil
+		getParams = url.Values{}
+	}
 
-	var newToken Token
-	err = cli.performRequest(req, &newToken) // perform the prepared request
+	// MaxPageSize only limits the size of a single page, collections spanning several pages
+	// are followed with their @odata.nextLink (see makeGETURLCall)
+	getParams.Add("$top", strconv.Itoa(MaxPageSize))
+
+	reqURL, err := cli.apiURL(apicall, getParams)
 	if err != nil {
-		return fmt.Errorf("error on getting msgraph Token: %v", err)
+		return err
 	}
-	cli.token = newToken
-	return err
+	return cli.makeGETURLCall(ctx, reqURL, v)
+}
+
+// makeGETURLCall performs a GET request against an absolute msgraph URL, e.g. an @odata.nextLink
+// returned by a previous API-call
+func (cli *Client) makeGETURLCall(ctx context.Context, reqURL string, v interface{}) error {
+	req, err := cli.newRequest(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	return cli.performRequest(req, v)
 }
 
-// makeGETAPICall performs an API-Call to the msgraph API. This func uses sync.Mutex to synchronize all API-calls
-func (cli *Client) makeGETAPICall(apicall string, getParams url.Values, v interface{}) error {
-	cli.Lock()
-	defer cli.Unlock() // unlock when the func returns
-	// Check token
-	if cli.token.WantsToBeRefreshed() { // Token not valid anymore?
-		err := cli.refreshToken()
+// makeAPICall performs an API-Call to the msgraph API with any method. in is sent JSON encoded
+// unless it is nil, the response is json-unmarshalled into v unless it is nil.
+func (cli *Client) makeAPICall(ctx context.Context, method, apicall string, params url.Values, in, v interface{}) error {
+	reqURL, err := cli.apiURL(apicall, params)
+	if err != nil {
+		return err
+	}
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
 		if err != nil {
-			return err
+			return fmt.Errorf("unable to encode request body: %v", err)
 		}
+		body = bytes.NewReader(data) // can be replayed on retries
 	}
-
-	reqURL, err := url.ParseRequestURI(BaseURL)
+	req, err := cli.newRequest(ctx, method, reqURL, body)
 	if err != nil {
-		return fmt.Errorf("unable to parse URI %v: %v", BaseURL, err)
+		return err
+	}
+	if in != nil {
+		req.Header.Add("Content-Type", "application/json")
 	}
 
-	// Add Version to API-Call, the leading slash is always added by the calling func
-	reqURL.Path = "/" + APIVersion + apicall
+	return cli.performRequest(req, v)
+}
 
-	req, err := http.NewRequest("GET", reqURL.String(), nil)
+// apiURL returns the absolute URL of an API-call
+func (cli *Client) apiURL(apicall string, params url.Values) (string, error) {
+	reqURL, err := url.ParseRequestURI(cli.graphURL())
 	if err != nil {
-		return fmt.Errorf("HTTP request error: %v", err)
+		return "", fmt.Errorf("unable to parse URI %v: %v", cli.graphURL(), err)
 	}
 
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", cli.token.GetAccessToken())
+	// Add Version to API-Call, the leading slash is always added by the calling func
+	reqURL.Path = strings.TrimSuffix(reqURL.Path, "/") + "/" + cli.version() + apicall
+	reqURL.RawQuery = params.Encode() // set query parameters
+	return reqURL.String(), nil
+}
 
-	if getParams == nil { // initialize getParams if it's nil
-		getParams = url.Values{}
+// newRequest returns a request against reqURL carrying the current token
+func (cli *Client) newRequest(ctx context.Context, method, reqURL string, body io.Reader) (*http.Request, error) {
+	token, err := cli.accessToken(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// TODO: Improve performance with using $skip & paging instead of retrieving all results with $top
-	// TODO: MaxPageSize is currently 999, if there are any time more than 999 entries this will make the program unpredictable... hence start to use paging (!)
-	getParams.Add("$top", strconv.Itoa(MaxPageSize))
-	req.URL.RawQuery = getParams.Encode() // set query parameters
-
-	return cli.performRequest(req, v)
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request error: %v", err)
+	}
+	req.Header.Add("Authorization", token)
+	return req, nil
 }
 
 // performRequest performs a pre-prepared http.Request and does the proper error-handling for it.
 // does a json.Unmarshal into the v interface{} and returns the error of it if everything went well so far.
+// The request is retried according to the retry policy of the Client.
 func (cli *Client) performRequest(req *http.Request, v interface{}) error {
-	httpClient := &http.Client{
-		Timeout: time.Second * 10,
-	}
-	resp, err := httpClient.Do(req)
+	resp, err := cli.do(req)
 	if err != nil {
-		return fmt.Errorf("HTTP response error: %v of http.Request: %v", err, req.URL)
+		return err
 	}
 	defer resp.Body.Close() // close body when func returns
 
-	body, err := ioutil.ReadAll(resp.Body) // read body first to append it to the error (if any)
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		// Hint: this will mostly be the case if the tenant ID can not be found, the Application ID can not be found or the clientSecret is incorrect.
-		// The cause will be described in the body, hence we have to return the body too for proper error-analysis
-		return NewErr(resp.StatusCode, body)
-	}
-
-	//fmt.Println("Body: ", string(body))
-
+	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return fmt.Errorf("HTTP response read error: %v of http.Request: %v", err, req.URL)
 	}
 
+	if v == nil { // the caller is not interested in the response, e.g. 204 No Content
+		return nil
+	}
 	return json.Unmarshal(body, &v) // return the error of the json unmarshal
 }
 
@@ -180,7 +259,10 @@ func (cli *Client) UnmarshalJSON(data []byte) error {
 	}
 
 	// get a token and return the error (if any)
-	err = cli.refreshToken()
+	cli.mu.Lock()
+	cli.src = nil // the credentials changed
+	cli.mu.Unlock()
+	err = cli.refreshToken(context.Background())
 	if err != nil {
 		return fmt.Errorf("can't get Token: %v", err)
 	}
@@ -1 +1,211 @@
 package drive_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+// newFakeGraph starts a local stand-in for the login and msgraph endpoints (see fakeGraphServer) and
+// returns a Client using it, configured with opts.
+func newFakeGraph(t *testing.T, api http.Handler, opts ...drive.Option) (*httptest.Server, *drive.Client) {
+	srv := fakeGraphServer(t, api)
+	opts = append([]drive.Option{drive.WithLoginBaseURL(srv.URL), drive.WithBaseURL(srv.URL),
+		drive.WithUserAgent("drive-test")}, opts...)
+	client, err := drive.NewGraphClient("tenant", "application", "secret", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, client
+}
+
+// fakeGraphServer starts a local stand-in for the login and msgraph endpoints. Token requests are
+// answered by the server itself, all other requests are passed to api.
+func fakeGraphServer(t *testing.T, api http.Handler) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		json.NewEncoder(w).Encode(map[string]string{
+			"token_type":   "Bearer",
+			"expires_on":   strconv.FormatInt(now.Add(time.Hour).Unix(), 10),
+			"not_before":   strconv.FormatInt(now.Add(-time.Minute).Unix(), 10),
+			"resource":     r.FormValue("resource"),
+			"access_token": "fake-token-v1",
+		})
+	})
+	mux.HandleFunc("/tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		// tokens for the national clouds are told apart by their access token
+		token := ""
+		for _, cloud := range drive.Clouds {
+			if r.FormValue("scope") == cloud.BaseURL+"/.default" {
+				token = "fake-token"
+				if cloud.Name != drive.CloudGlobal.Name {
+					token += "-" + cloud.Name
+				}
+			}
+		}
+		if token == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_scope"}`)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token_type":   "Bearer",
+			"expires_in":   3599,
+			"access_token": token,
+		})
+	})
+	mux.Handle("/", api)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_Options(t *testing.T) {
+	var gotAuth, gotAgent, gotPath string
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotAgent, gotPath = r.Header.Get("Authorization"), r.UserAgent(), r.URL.Path
+		fmt.Fprint(w, `{"id":"root-id","name":"root","folder":{"childCount":0}}`)
+	}))
+	item, err := client.GetDrive("drive-id").Item("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != "root-id" || !item.IsFolder() {
+		t.Errorf("unexpected item %#v", item)
+	}
+	if gotAuth != "Bearer fake-token" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if gotAgent != "drive-test" {
+		t.Errorf("User-Agent = %q", gotAgent)
+	}
+	if gotPath != "/v1.0/drives/drive-id/items/root" {
+		t.Errorf("path = %q", gotPath)
+	}
+}
+
+func TestClient_Retry(t *testing.T) {
+	calls := 0
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"code":"activityLimitReached"}}`)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			fmt.Fprint(w, `{"id":"root-id"}`)
+		}
+	})
+	_, client := newFakeGraph(t, api, drive.WithRetryPolicy(drive.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+	if _, err := client.GetDrive("drive-id").Item("/"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+
+	calls = 0
+	_, client = newFakeGraph(t, api, drive.WithRetryPolicy(drive.NoRetry))
+	_, err := client.GetDrive("drive-id").Item("/")
+	if re, ok := err.(*drive.ReqError); !ok || re.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected throttling error, got %v", err)
+	}
+}
+
+func TestClient_Concurrent(t *testing.T) {
+	const n = 4
+	arrived := make(chan struct{}, n)
+	release := make(chan struct{})
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		fmt.Fprint(w, `{"id":"root-id"}`)
+	}))
+	drv := client.GetDrive("drive-id")
+
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := drv.Item("/")
+			errs <- err
+		}()
+	}
+	// all requests have to reach the server before any of them is answered
+	for i := 0; i < n; i++ {
+		select {
+		case <-arrived:
+		case <-time.After(5 * time.Second):
+			close(release)
+			t.Fatalf("only %d of %d requests are in flight concurrently", i, n)
+		}
+	}
+	close(release)
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestClient_V1Endpoint(t *testing.T) {
+	var gotAuth string
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"id":"root-id"}`)
+	}), drive.WithV1Endpoint())
+	if _, err := client.GetDrive("drive-id").Item("/"); err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "Bearer fake-token-v1" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+}
+
+func TestClient_V1Fallback(t *testing.T) {
+	var v2Calls int
+	var gotAuth string
+	srv := fakeGraphServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bad-tenant/oauth2/v2.0/token":
+			v2Calls++
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client","error_description":"AADSTS7000215: Invalid client secret"}`)
+		case "/bad-tenant/oauth2/token":
+			t.Error("invalid credentials have been retried at the v1 endpoint")
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			gotAuth = r.Header.Get("Authorization")
+			fmt.Fprint(w, `{"id":"root-id"}`)
+		}
+	}))
+
+	// the fake v2.0 endpoint does not know the scope of a custom cloud
+	custom := drive.Cloud{Name: "custom", LoginBaseURL: srv.URL, BaseURL: srv.URL}
+	client, err := drive.NewGraphClient("tenant", "application", "secret", drive.WithCloud(custom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetDrive("drive-id").Item("/"); err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "Bearer fake-token-v1" {
+		t.Errorf("Authorization = %q, the token has not been requested from the v1 endpoint", gotAuth)
+	}
+
+	if _, err := drive.NewGraphClient("bad-tenant", "application", "secret", drive.WithCloud(custom)); err == nil {
+		t.Error("invalid credentials did not fail")
+	}
+	if v2Calls != 1 {
+		t.Errorf("expected a single v2.0 token request, got %d", v2Calls)
+	}
+}
@@ -0,0 +1,51 @@
+package drive
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Cloud describes a deployment of msgraph, hence the global service or one of the national clouds.
+// See https://docs.microsoft.com/en-us/graph/deployments
+type Cloud struct {
+	Name         string // short name used by CloudByName
+	LoginBaseURL string // the authority host tokens are acquired from
+	BaseURL      string // the msgraph host, tokens are acquired for this resource as well
+}
+
+var (
+	// CloudGlobal is the global msgraph service, this is the default
+	CloudGlobal = Cloud{Name: "global", LoginBaseURL: LoginBaseURL, BaseURL: BaseURL}
+	// CloudUSGovernment is the msgraph service for US Government L4 (GCC High)
+	CloudUSGovernment = Cloud{Name: "usgov", LoginBaseURL: "https://login.microsoftonline.us", BaseURL: "https://graph.microsoft.us"}
+	// CloudUSGovernmentDoD is the msgraph service for US Government L5 (DOD)
+	CloudUSGovernmentDoD = Cloud{Name: "usgov-dod", LoginBaseURL: "https://login.microsoftonline.us", BaseURL: "https://dod-graph.microsoft.us"}
+	// CloudChina is the msgraph service operated by 21Vianet in China
+	CloudChina = Cloud{Name: "china", LoginBaseURL: "https://login.chinacloudapi.cn", BaseURL: "https://microsoftgraph.chinacloudapi.cn"}
+)
+
+// Clouds lists all known clouds
+var Clouds = []Cloud{CloudGlobal, CloudUSGovernment, CloudUSGovernmentDoD, CloudChina}
+
+// CloudByName returns the cloud with the given name (case insensitive). An empty name selects CloudGlobal.
+func CloudByName(name string) (Cloud, error) {
+	if name == "" {
+		return CloudGlobal, nil
+	}
+	for _, c := range Clouds {
+		if strings.EqualFold(c.Name, name) {
+			return c, nil
+		}
+	}
+	return Cloud{}, fmt.Errorf("unknown cloud %q", name)
+}
+
+// WithCloud makes the Client use cloud. The login endpoint, the msgraph endpoint and the resource
+// tokens are acquired for are switched together.
+func WithCloud(cloud Cloud) Option {
+	return func(cli *Client) {
+		cli.loginBaseURL = cloud.LoginBaseURL
+		cli.baseURL = cloud.BaseURL
+		cli.resource = cloud.BaseURL
+	}
+}
@@ -0,0 +1,96 @@
+package drive_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+// redirectTransport sends all requests to target and records the hosts they were meant for
+type redirectTransport struct {
+	target *url.URL
+
+	mu    sync.Mutex
+	hosts map[string]bool
+}
+
+func (rt *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.hosts[req.URL.Host] = true
+	rt.mu.Unlock()
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = rt.target.Scheme, rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithCloud(t *testing.T) {
+	var gotAuth, gotPath string
+	srv := fakeGraphServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotPath = r.Header.Get("Authorization"), r.URL.Path
+		fmt.Fprint(w, `{"id":"root-id"}`)
+	}))
+	target, _ := url.Parse(srv.URL)
+	rt := &redirectTransport{target: target, hosts: map[string]bool{}}
+	client, err := drive.NewGraphClient("tenant", "application", "secret",
+		drive.WithCloud(drive.CloudUSGovernment), drive.WithTransport(rt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetDrive("drive-id").Item("/"); err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "Bearer fake-token-usgov" {
+		t.Errorf("Authorization = %q, the token has not been acquired for the cloud", gotAuth)
+	}
+	if gotPath != "/v1.0/drives/drive-id/items/root" {
+		t.Errorf("path = %q", gotPath)
+	}
+	for _, host := range []string{"login.microsoftonline.us", "graph.microsoft.us"} {
+		if !rt.hosts[host] {
+			t.Errorf("no request has been sent to %v, got %v", host, rt.hosts)
+		}
+	}
+
+	if _, err := drive.CloudByName("germany"); err == nil {
+		t.Error("the retired Microsoft Cloud Germany is still known")
+	}
+}
+
+func TestWithCloud_TokenHTTPClient(t *testing.T) {
+	var gotAuth string
+	srv := fakeGraphServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"id":"root-id"}`)
+	}))
+	target, _ := url.Parse(srv.URL)
+	tokenRT := &redirectTransport{target: target, hosts: map[string]bool{}}
+	apiRT := &redirectTransport{target: target, hosts: map[string]bool{}}
+
+	// the token source performs its requests with its own http.Client but for the cloud of the Client
+	src := &drive.ClientCredentials{
+		TenantID:      "tenant",
+		ApplicationID: "application",
+		ClientSecret:  "secret",
+		HTTPClient:    &http.Client{Transport: tokenRT},
+	}
+	client, err := drive.NewClient(src, drive.WithCloud(drive.CloudUSGovernment), drive.WithTransport(apiRT))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetDrive("drive-id").Item("/"); err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "Bearer fake-token-usgov" {
+		t.Errorf("Authorization = %q, the token has not been acquired for the cloud", gotAuth)
+	}
+	if len(tokenRT.hosts) != 1 || !tokenRT.hosts["login.microsoftonline.us"] {
+		t.Errorf("token requested from %v", tokenRT.hosts)
+	}
+	if len(apiRT.hosts) != 1 || !apiRT.hosts["graph.microsoft.us"] {
+		t.Errorf("API-calls sent to %v", apiRT.hosts)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
 	"io/ioutil"
@@ -38,6 +39,8 @@ type Config struct {
 	TenantID      string
 	ApplicationID string
 	ClientSecret  string
+	Cloud         string
+	TokenFile     string // encrypted token cache, the key is read from $MSGRAPH_TOKEN_CACHE_KEY
 	DriveID       string
 	View          string
 	Listen        string
@@ -65,10 +68,24 @@ func init() {
 		TenantID:      os.Getenv("TENANT_ID"),
 		ApplicationID: os.Getenv("APP_ID"),
 		ClientSecret:  os.Getenv("CLI_SECRET"),
+		Cloud:         os.Getenv("CLOUD"),
+		TokenFile:     os.Getenv("TOKEN_FILE"),
 		View:          os.Getenv("DRV_VIEW"),
 		DriveID:       os.Getenv("DRIVE_ID"),
 	}
-	cli, err := drive.NewGraphClient(conf.TenantID, conf.ApplicationID, conf.ClientSecret)
+	cloud, err := drive.CloudByName(conf.Cloud)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	opts := []drive.Option{drive.WithCloud(cloud)}
+	if conf.TokenFile != "" {
+		cache, err := drive.NewFileTokenCache(conf.TokenFile, "")
+		if err != nil {
+			log.Fatalln(err)
+		}
+		opts = append(opts, drive.WithTokenCache(cache))
+	}
+	cli, err := drive.NewGraphClient(conf.TenantID, conf.ApplicationID, conf.ClientSecret, opts...)
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -94,12 +111,12 @@ func main() {
 	lambda.Start(Serve)
 }
 
-func Serve(event Event) (Response, error) {
+func Serve(ctx context.Context, event Event) (Response, error) {
 	rawPath := event.PathParameters["proxy"]
 	path := filepath.Join(os.Getenv("BASE_DIR"), rawPath)
 
 	// try to handle as an directory
-	items, err := drv.ListChildren(path)
+	items, err := drv.ListChildrenContext(ctx, path)
 	if err != nil {
 		// whether not found or other error
 		switch err.(type) {
@@ -121,7 +138,7 @@ func Serve(event Event) (Response, error) {
 
 	// if not a directory
 	if len(items) == 0 {
-		item, err := drv.Item(path)
+		item, err := drv.ItemContext(ctx, path)
 		if err != nil {
 			return Response{
 				IsBase64Encoded: false,
@@ -1,16 +1,25 @@
 package main
 
 import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"html/template"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -21,15 +30,32 @@ import (
 type Config struct {
 	TenantID      string `yaml:"tenant"`
 	ApplicationID string `yaml:"application"`
+	Auth          string `yaml:"auth"` // "secret" (default), "certificate", "delegated", "managed" or "workload"
 	ClientSecret  string `yaml:"secret"`
-	DriveID       string `yaml:"drive"`
-	View          string `yaml:"view"`
-	Listen        string `yaml:"listen"`
+	Cloud         string `yaml:"cloud"`
+
+	Certificate         string `yaml:"certificate"`          // PEM or PKCS#12 (.pfx, .p12) file
+	CertificateKey      string `yaml:"certificate_key"`      // PEM private key, if not contained in certificate
+	CertificatePassword string `yaml:"certificate_password"` // password of the PKCS#12 file
+
+	IdentityEndpoint string `yaml:"identity_endpoint"` // token endpoint of the managed identity, defaults to the Azure Instance Metadata Service
+	FederatedToken   string `yaml:"federated_token"`   // federated token file of the workload identity, defaults to $AZURE_FEDERATED_TOKEN_FILE
+
+	Redirect  string `yaml:"redirect"`   // redirect URI of the login, defaults to http://localhost:<port of listen>/.auth/callback
+	TokenFile string `yaml:"token_file"` // encrypted token cache, required for delegated auth to keep the refresh token
+	TokenKey  string `yaml:"token_key"`  // passphrase of token_file, defaults to $MSGRAPH_TOKEN_CACHE_KEY
+
+	DriveID string `yaml:"drive"`
+	View    string `yaml:"view"`
+	Listen  string `yaml:"listen"`
 }
 
 type DrvSrv struct {
-	Drive *drive.Drive
+	mu    sync.RWMutex
+	Drive *drive.Drive // nil until the login completed if auth is delegated
 	Tpl   *template.Template
+
+	setupSecret string // required to log in as long as there is no drive, see setupOnly
 }
 
 type DataItem struct {
@@ -49,17 +75,15 @@ type Data struct {
 func main() {
 	confPath := flag.String("conf", "config.yaml", "config file")
 	flag.Parse()
-	if len(os.Args) > 1 {
-		if os.Args[1] == "new" {
-			confFile, err := genConfig(&Config{Listen: ":8086"})
-			if err != nil {
-				log.Fatalln(err)
-			}
-			if err := ioutil.WriteFile(*confPath, confFile, 0644); err != nil {
-				log.Fatalln(err)
-			}
-			return
+	if flag.Arg(0) == "new" {
+		confFile, err := genConfig(&Config{Listen: ":8086"})
+		if err != nil {
+			log.Fatalln(err)
 		}
+		if err := ioutil.WriteFile(*confPath, confFile, 0644); err != nil {
+			log.Fatalln(err)
+		}
+		return
 	}
 	confFile, err := ioutil.ReadFile(*confPath)
 	if err != nil {
@@ -69,15 +93,36 @@ func main() {
 	if err != nil {
 		log.Fatalln(err)
 	}
-	cli, err := drive.NewGraphClient(conf.TenantID, conf.ApplicationID, conf.ClientSecret)
-	if err != nil {
+	if flag.Arg(0) == "login" {
+		if err := deviceLogin(conf); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+	drvH := NewDrvHandler(nil)
+	cli, err := newClient(conf)
+	switch {
+	case err == nil:
+		drvH.SetDrive(cli.GetDrive(conf.DriveID))
+	case conf.Auth == "delegated" && errors.Is(err, drive.ErrNoCachedToken):
+		// anybody reaching the server could log in with their account otherwise
+		drvH.setupSecret, err = newSetupSecret()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		log.Printf("not logged in yet, visit /.auth/login?secret=%v or run \"login\"\n", drvH.setupSecret)
+	default:
 		log.Fatalln(err)
 	}
-	drvH := NewDrvHandler(cli.GetDrive(conf.DriveID))
 	drvH.Tpl, err = template.New("index.html").ParseFiles(conf.View)
 	http.Handle("/", drvH)
+	if conf.Auth == "delegated" {
+		if err := handleLogin(conf, drvH); err != nil {
+			log.Fatalln(err)
+		}
+	}
 	go func() {
-		exitCh := make(chan os.Signal)
+		exitCh := make(chan os.Signal, 1)
 		signal.Notify(exitCh, os.Kill, os.Interrupt)
 		for range exitCh {
 			fmt.Println("Bye!")
@@ -90,6 +135,164 @@ func main() {
 	}
 }
 
+func newClient(conf *Config) (*drive.Client, error) {
+	cloud, err := drive.CloudByName(conf.Cloud)
+	if err != nil {
+		return nil, err
+	}
+	opts := []drive.Option{drive.WithCloud(cloud)}
+	cache, err := tokenCache(conf)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		opts = append(opts, drive.WithTokenCache(cache))
+	}
+	switch conf.Auth {
+	case "", "secret":
+		return drive.NewGraphClient(conf.TenantID, conf.ApplicationID, conf.ClientSecret, opts...)
+	case "certificate":
+		cert, key, err := loadCertificate(conf)
+		if err != nil {
+			return nil, err
+		}
+		return drive.NewGraphClientWithCertificate(conf.TenantID, conf.ApplicationID, cert, key, opts...)
+	case "delegated":
+		if cache == nil {
+			return nil, fmt.Errorf("delegated auth requires token_file")
+		}
+		token, err := cache.Load(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return drive.NewClient(delegatedConfig(conf).TokenSource(token), opts...)
+	case "managed":
+		return drive.NewClient(&drive.ManagedIdentity{ClientID: conf.ApplicationID, Endpoint: conf.IdentityEndpoint}, opts...)
+	case "workload":
+		return drive.NewClient(&drive.WorkloadIdentity{
+			TenantID:      conf.TenantID,
+			ApplicationID: conf.ApplicationID,
+			TokenFile:     conf.FederatedToken,
+		}, opts...)
+	default:
+		return nil, fmt.Errorf("unknown auth %q", conf.Auth)
+	}
+}
+
+// tokenCache returns the cache of token_file, nil if it is not configured
+func tokenCache(conf *Config) (drive.TokenCache, error) {
+	if conf.TokenFile == "" {
+		return nil, nil
+	}
+	return drive.NewFileTokenCache(conf.TokenFile, conf.TokenKey)
+}
+
+func delegatedConfig(conf *Config) drive.DelegatedConfig {
+	return drive.DelegatedConfig{
+		TenantID:      conf.TenantID,
+		ApplicationID: conf.ApplicationID,
+		ClientSecret:  conf.ClientSecret,
+	}
+}
+
+// handleLogin registers the routes capturing the initial consent of a delegated login.
+// They are only served as long as nobody logged in.
+func handleLogin(conf *Config, drvH *DrvSrv) error {
+	cloud, err := drive.CloudByName(conf.Cloud)
+	if err != nil {
+		return err
+	}
+	flow := &drive.AuthCodeFlow{
+		DelegatedConfig: delegatedConfig(conf),
+		RedirectURL:     conf.Redirect,
+	}
+	flow.LoginBaseURL, flow.Resource = cloud.LoginBaseURL, cloud.BaseURL
+	if flow.RedirectURL == "" {
+		_, port, err := net.SplitHostPort(conf.Listen)
+		if err != nil {
+			return fmt.Errorf("unable to derive redirect from listen %q: %v", conf.Listen, err)
+		}
+		flow.RedirectURL = "http://localhost:" + port + "/.auth/callback"
+	}
+	onToken := func(ctx context.Context, token drive.Token) error {
+		if err := saveToken(conf, token); err != nil {
+			return err
+		}
+		cache, err := tokenCache(conf)
+		if err != nil {
+			return err
+		}
+		cli, err := drive.NewClientContext(ctx, flow.TokenSource(token), drive.WithCloud(cloud), drive.WithTokenCache(cache))
+		if err != nil {
+			return err
+		}
+		drvH.SetDrive(cli.GetDrive(conf.DriveID))
+		return nil
+	}
+	http.Handle("/.auth/login", drvH.setupOnly(flow.LoginHandler()))
+	http.Handle("/.auth/callback", drvH.setupOnly(flow.CallbackHandler(onToken)))
+	return nil
+}
+
+// deviceLogin performs a delegated login with the device code flow, hence without a browser on
+// this machine, and stores the token in the token file.
+func deviceLogin(conf *Config) error {
+	if conf.Auth != "delegated" {
+		return fmt.Errorf("login requires auth \"delegated\", got %q", conf.Auth)
+	}
+	cloud, err := drive.CloudByName(conf.Cloud)
+	if err != nil {
+		return err
+	}
+	flow := &drive.DeviceCodeFlow{DelegatedConfig: delegatedConfig(conf)}
+	flow.LoginBaseURL, flow.Resource = cloud.LoginBaseURL, cloud.BaseURL
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	token, err := flow.Login(ctx, func(dc *drive.DeviceCode) {
+		fmt.Println(dc.Message)
+	})
+	if err != nil {
+		return err
+	}
+	if err := saveToken(conf, token); err != nil {
+		return err
+	}
+	fmt.Println("Login succeeded.")
+	return nil
+}
+
+// saveToken stores the token of a delegated login in the token file
+func saveToken(conf *Config, token drive.Token) error {
+	cache, err := tokenCache(conf)
+	if err != nil {
+		return err
+	}
+	if cache == nil {
+		return fmt.Errorf("token_file is not configured")
+	}
+	return cache.Store(context.Background(), token)
+}
+
+func loadCertificate(conf *Config) (*x509.Certificate, crypto.PrivateKey, error) {
+	data, err := ioutil.ReadFile(conf.Certificate)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch strings.ToLower(filepath.Ext(conf.Certificate)) {
+	case ".pfx", ".p12":
+		return drive.ParseCertificatePKCS12(data, conf.CertificatePassword)
+	}
+	var key []byte
+	if conf.CertificateKey != "" {
+		key, err = ioutil.ReadFile(conf.CertificateKey)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return drive.ParseCertificatePEM(data, key)
+}
+
 func genConfig(conf *Config) ([]byte, error) {
 	return yaml.Marshal(conf)
 }
@@ -106,9 +309,61 @@ func NewDrvHandler(drv *drive.Drive) *DrvSrv {
 	}
 }
 
+func (ds *DrvSrv) SetDrive(drv *drive.Drive) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.Drive = drv
+}
+
+func (ds *DrvSrv) drive() *drive.Drive {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	return ds.Drive
+}
+
+// setupCookie carries the setup secret from the login to the callback
+const setupCookie = "drive_setup"
+
+// newSetupSecret returns a random secret which has to be known to log in
+func newSetupSecret() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// setupOnly serves h only as long as there is no drive, and only to requests presenting the setup
+// secret logged at startup, either as secret parameter or as the cookie set by the first of them.
+func (ds *DrvSrv) setupOnly(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if ds.drive() != nil || ds.setupSecret == "" {
+			http.NotFound(resp, req)
+			return
+		}
+		secret := req.URL.Query().Get("secret")
+		if secret == "" {
+			if c, err := req.Cookie(setupCookie); err == nil {
+				secret = c.Value
+			}
+		}
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(ds.setupSecret)) != 1 {
+			http.Error(resp, "missing or wrong setup secret, see the log of the server", http.StatusForbidden)
+			return
+		}
+		http.SetCookie(resp, &http.Cookie{Name: setupCookie, Value: secret, Path: "/.auth/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+		h.ServeHTTP(resp, req)
+	})
+}
+
 func (ds *DrvSrv) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	path := req.URL.Path
-	items, err := ds.Drive.ListChildren(path)
+	drv := ds.drive()
+	if drv == nil {
+		http.Redirect(resp, req, "/.auth/login", http.StatusTemporaryRedirect)
+		return
+	}
+	items, err := drv.ListChildrenContext(req.Context(), path)
 	if err != nil {
 		switch err.(type) {
 		case *drive.ReqError:
@@ -124,7 +379,7 @@ func (ds *DrvSrv) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	}
 
 	if len(items) == 0 {
-		item, err := ds.Drive.Item(path)
+		item, err := drv.ItemContext(req.Context(), path)
 		if err != nil {
 			resp.Write([]byte(err.Error()))
 			return
@@ -1,5 +1,7 @@
 package drive
 
+import "time"
+
 // LoginBaseURL represents the basic url used to acquire a token for the msgraph api
 const LoginBaseURL string = "https://login.microsoftonline.com"
 
@@ -9,5 +11,21 @@ const BaseURL string = "https://graph.microsoft.com"
 // APIVersion represents the APIVersion of msgraph used by this implementation
 const APIVersion string = "v1.0"
 
-// MaxPageSize is the maximum Page size for an API-call. This will be rewritten to use paging some day. Currently limits environments to 999 entries (e.g. Users, CalendarEvents etc.)
+// MaxPageSize is the maximum Page size for an API-call. Collections with more entries are split into
+// several pages which are linked by @odata.nextLink
 const MaxPageSize int = 999
+
+// DefaultTimeout is the time limit of a single request if no other has been set with WithTimeout or WithHTTPClient
+const DefaultTimeout time.Duration = 10 * time.Second
+
+// MaxSimpleUploadSize is the maximum size of a file uploaded with Drive.Put. Larger files
+// have to be uploaded with an upload session.
+const MaxSimpleUploadSize int64 = 4 * 1024 * 1024
+
+// UploadChunkMultiple is the unit of the chunks of an upload session, every chunk but the last one
+// has to be a multiple of it
+const UploadChunkMultiple int64 = 320 * 1024
+
+// DefaultUploadChunkSize is the size of the chunks of an upload session if no other has been set.
+// Chunks are not bound to DefaultTimeout, see UploadOptions.ChunkTimeout.
+const DefaultUploadChunkSize int64 = 10 * UploadChunkMultiple
@@ -0,0 +1,228 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+)
+
+// DefaultCopyPollInterval is the time CopyOperation.Wait waits between two polls if no other has been set
+const DefaultCopyPollInterval = time.Second
+
+// Copy statuses reported by the monitor of a CopyOperation
+const (
+	CopyNotStarted = "notStarted"
+	CopyInProgress = "inProgress"
+	CopyCompleted  = "completed"
+	CopyFailed     = "failed"
+)
+
+// CopyOperation is a copy performed asynchronously by msgraph, see Drive.Copy. Its progress is
+// reported by a monitor, which is polled by Poll and Wait.
+type CopyOperation struct {
+	MonitorURL         string        // pre-authenticated URL reporting the progress
+	Status             string        // the status reported by the last poll, e.g. CopyInProgress
+	PercentageComplete float64       // the progress reported by the last poll
+	ResourceID         string        // the ID of the copy, reported once it completed
+	Interval           time.Duration // time Wait waits between two polls, defaults to DefaultCopyPollInterval
+
+	dst *Drive // the drive the copy is created in
+}
+
+// Copy copies the item at srcPath into the folder at dstParentPath of the same drive, folders are copied
+// along with their content. The copy is called name unless name is empty. conflict tells what to do if
+// the destination exists already.
+//
+// The copy is performed asynchronously by msgraph, the returned CopyOperation reports its progress.
+func (drv *Drive) Copy(srcPath, dstParentPath, name string, conflict ConflictBehavior) (*CopyOperation, error) {
+	return drv.CopyToDriveContext(context.Background(), srcPath, drv, dstParentPath, name, conflict)
+}
+
+// CopyContext is like Copy but performs the API-calls with ctx.
+func (drv *Drive) CopyContext(ctx context.Context, srcPath, dstParentPath, name string, conflict ConflictBehavior) (*CopyOperation, error) {
+	return drv.CopyToDriveContext(ctx, srcPath, drv, dstParentPath, name, conflict)
+}
+
+// CopyToDrive is like Copy but copies the item into the folder at dstParentPath of the drive dst, e.g.
+// a drive of another user or a SharePoint site, which has to be accessible with the Client of drv.
+func (drv *Drive) CopyToDrive(srcPath string, dst *Drive, dstParentPath, name string, conflict ConflictBehavior) (*CopyOperation, error) {
+	return drv.CopyToDriveContext(context.Background(), srcPath, dst, dstParentPath, name, conflict)
+}
+
+// CopyToDriveContext is like CopyToDrive but performs the API-calls with ctx.
+func (drv *Drive) CopyToDriveContext(ctx context.Context, srcPath string, dst *Drive, dstParentPath, name string, conflict ConflictBehavior) (*CopyOperation, error) {
+	if isRoot(srcPath) {
+		return nil, fmt.Errorf("the root folder can not be copied")
+	}
+	parent, err := dst.folder(ctx, dstParentPath)
+	if err != nil {
+		return nil, err
+	}
+	return drv.copy(ctx, drv.itemSource(srcPath), dst, parent, name, conflict)
+}
+
+// CopyByID is like Copy but addresses the item and the destination folder by their IDs.
+func (drv *Drive) CopyByID(id, dstParentID, name string, conflict ConflictBehavior) (*CopyOperation, error) {
+	return drv.CopyByIDToDriveContext(context.Background(), id, drv, dstParentID, name, conflict)
+}
+
+// CopyByIDContext is like CopyByID but performs the API-calls with ctx.
+func (drv *Drive) CopyByIDContext(ctx context.Context, id, dstParentID, name string, conflict ConflictBehavior) (*CopyOperation, error) {
+	return drv.CopyByIDToDriveContext(ctx, id, drv, dstParentID, name, conflict)
+}
+
+// CopyByIDToDrive is like CopyToDrive but addresses the item and the destination folder by their IDs.
+func (drv *Drive) CopyByIDToDrive(id string, dst *Drive, dstParentID, name string, conflict ConflictBehavior) (*CopyOperation, error) {
+	return drv.CopyByIDToDriveContext(context.Background(), id, dst, dstParentID, name, conflict)
+}
+
+// CopyByIDToDriveContext is like CopyByIDToDrive but performs the API-calls with ctx.
+func (drv *Drive) CopyByIDToDriveContext(ctx context.Context, id string, dst *Drive, dstParentID, name string, conflict ConflictBehavior) (*CopyOperation, error) {
+	if id == "" {
+		return nil, fmt.Errorf("item ID is empty")
+	}
+	parent, err := dst.ItemByIDContext(ctx, dstParentID)
+	if err != nil {
+		return nil, err
+	}
+	if !parent.IsFolder() {
+		return nil, fmt.Errorf("%v is not a folder", parent.Name)
+	}
+	return drv.copy(ctx, drv.idSource(id), dst, parent, name, conflict)
+}
+
+// copy starts the copy of the item of the API-call source into the folder parent of dst
+func (drv *Drive) copy(ctx context.Context, source string, dst *Drive, parent *Item, name string, conflict ConflictBehavior) (*CopyOperation, error) {
+	parentRef := map[string]string{"id": parent.ID}
+	if driveID := parent.ParentReference.DriveID; driveID != "" {
+		parentRef["driveId"] = driveID
+	} else if dst.ID != MyDriveID {
+		parentRef["driveId"] = dst.ID
+	}
+	in := map[string]interface{}{"parentReference": parentRef}
+	if name != "" {
+		in["name"] = name
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode request body: %v", err)
+	}
+
+	reqURL, err := drv.Client.apiURL(actionSource(source, "copy"), conflict.params())
+	if err != nil {
+		return nil, err
+	}
+	req, err := drv.Client.newRequest(ctx, "POST", reqURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	// the monitor URL is only reported in the Location header of the 202 Accepted response
+	resp, err := drv.Client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	monitor := resp.Header.Get("Location")
+	if monitor == "" {
+		return nil, fmt.Errorf("copy of %v did not report a monitor URL", source)
+	}
+	return &CopyOperation{MonitorURL: monitor, Status: CopyNotStarted, dst: dst}, nil
+}
+
+// Done reports whether the copy completed or failed according to the last poll
+func (op *CopyOperation) Done() bool {
+	return op.Status == CopyCompleted || op.Status == CopyFailed
+}
+
+// Poll queries the monitor and updates Status, PercentageComplete and ResourceID. A failed
+// copy is reported as error.
+func (op *CopyOperation) Poll(ctx context.Context) error {
+	// the monitor is pre-authenticated and redirects to the copy once it completed,
+	// which must not be followed without an Authorization header
+	var redirect string
+	c := op.dst.Client.copyHTTPClient()
+	c.CheckRedirect = func(req *http.Request, _ []*http.Request) error {
+		redirect = req.URL.Path
+		return http.ErrUseLastResponse
+	}
+	cli := &Client{httpClient: c, userAgent: op.dst.Client.userAgent, retry: op.dst.Client.retry}
+	req, err := http.NewRequestWithContext(ctx, "GET", op.MonitorURL, nil)
+	if err != nil {
+		return fmt.Errorf("HTTP request error: %v", err)
+	}
+
+	status := struct {
+		Status             string  `json:"status"`
+		PercentageComplete float64 `json:"percentageComplete"`
+		ResourceID         string  `json:"resourceId"`
+		Error              *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}{}
+	err = cli.performRequest(req, &status)
+	var re *ReqError
+	if errors.As(err, &re) && re.StatusCode >= 300 && re.StatusCode < 400 { // redirected to the copy
+		status.Status, status.PercentageComplete = CopyCompleted, 100
+		status.ResourceID = path.Base(redirect)
+	} else if err != nil {
+		return err
+	}
+
+	op.Status, op.PercentageComplete = status.Status, status.PercentageComplete
+	if status.ResourceID != "" {
+		op.ResourceID = status.ResourceID
+	}
+	if op.Status == CopyFailed {
+		if status.Error != nil {
+			return fmt.Errorf("copy failed: %v: %v", status.Error.Code, status.Error.Message)
+		}
+		return fmt.Errorf("copy failed")
+	}
+	return nil
+}
+
+// Wait polls the monitor until the copy completed and returns the copy. It fails if the copy
+// failed or ctx is done.
+func (op *CopyOperation) Wait(ctx context.Context) (*Item, error) {
+	interval := op.Interval
+	if interval <= 0 {
+		interval = DefaultCopyPollInterval
+	}
+	for {
+		if err := op.Poll(ctx); err != nil {
+			return nil, err
+		}
+		if op.Status == CopyCompleted {
+			return op.Item(ctx)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Item returns the copy. It must only be called once the copy completed.
+func (op *CopyOperation) Item(ctx context.Context) (*Item, error) {
+	if op.ResourceID == "" {
+		return nil, fmt.Errorf("copy has not completed yet")
+	}
+	marsh := &Item{}
+	err := op.dst.Client.makeGETAPICall(ctx, op.dst.idSource(op.ResourceID), nil, marsh)
+	if err != nil {
+		return nil, err
+	}
+	return marsh, nil
+}
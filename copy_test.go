@@ -0,0 +1,152 @@
+package drive_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+func TestDrive_Copy(t *testing.T) {
+	var body, conflict string
+	polls := 0
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1.0/drives/other-id/root:/dest":
+			fmt.Fprint(w, `{"id":"dest-id","folder":{},"parentReference":{"driveId":"other-id"}}`)
+		case r.Method == "POST" && r.URL.Path == "/v1.0/drives/drive-id/root:/src/a.txt:/copy":
+			in := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&in)
+			data, _ := json.Marshal(in)
+			body, conflict = string(data), r.URL.Query().Get("@microsoft.graph.conflictBehavior")
+			w.Header().Set("Location", "http://"+r.Host+"/monitor/1")
+			w.WriteHeader(http.StatusAccepted)
+		case r.URL.Path == "/monitor/1":
+			if r.Header.Get("Authorization") != "" {
+				t.Error("monitor URL requested with Authorization header")
+			}
+			polls++
+			if polls < 3 {
+				fmt.Fprintf(w, `{"status":"inProgress","percentageComplete":%d}`, polls*40)
+				return
+			}
+			fmt.Fprint(w, `{"status":"completed","percentageComplete":100,"resourceId":"copy-id"}`)
+		case r.Method == "GET" && r.URL.Path == "/v1.0/drives/other-id/items/copy-id":
+			fmt.Fprint(w, `{"id":"copy-id","name":"b.txt"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	drv := client.GetDrive("drive-id")
+
+	op, err := drv.CopyToDrive("/src/a.txt", client.GetDrive("other-id"), "/dest", "b.txt", drive.ConflictRename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"name":"b.txt","parentReference":{"driveId":"other-id","id":"dest-id"}}`; body != expected {
+		t.Errorf("unexpected body %s", body)
+	}
+	if conflict != "rename" {
+		t.Errorf("unexpected conflict behavior %q", conflict)
+	}
+
+	if err := op.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if op.Status != drive.CopyInProgress || op.PercentageComplete != 40 || op.Done() {
+		t.Errorf("unexpected operation %#v", op)
+	}
+	op.Interval = time.Millisecond
+	item, err := op.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != "copy-id" || op.ResourceID != "copy-id" || !op.Done() || polls != 3 {
+		t.Errorf("unexpected item %#v after %d polls", item, polls)
+	}
+}
+
+func TestCopyOperation_Redirect(t *testing.T) {
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1.0/drives/drive-id/items/root":
+			fmt.Fprint(w, `{"id":"root-id","folder":{}}`)
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, ":/copy"):
+			monitor := "/monitor/1"
+			if strings.Contains(r.URL.Path, "b.txt") {
+				monitor = "/monitor/2"
+			}
+			w.Header().Set("Location", "http://"+r.Host+monitor)
+			w.WriteHeader(http.StatusAccepted)
+		case r.URL.Path == "/monitor/1":
+			w.Header().Set("Location", "http://"+r.Host+"/v1.0/drives/drive-id/items/copy-id")
+			w.WriteHeader(http.StatusSeeOther)
+		case r.URL.Path == "/monitor/2":
+			fmt.Fprint(w, `{"status":"failed","error":{"code":"nameAlreadyExists","message":"exists"}}`)
+		case r.Method == "GET" && r.URL.Path == "/v1.0/drives/drive-id/items/copy-id":
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+				t.Error("item requested without Authorization header")
+			}
+			fmt.Fprint(w, `{"id":"copy-id"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	drv := client.GetDrive("drive-id")
+
+	op, err := drv.Copy("a.txt", "", "", drive.ConflictDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := op.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != "copy-id" || op.PercentageComplete != 100 {
+		t.Errorf("unexpected item %#v", item)
+	}
+
+	op, err = drv.Copy("b.txt", "", "", drive.ConflictDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := op.Wait(context.Background()); err == nil || !strings.Contains(err.Error(), "nameAlreadyExists") {
+		t.Errorf("expected failed copy, got %v", err)
+	}
+	if op.Status != drive.CopyFailed || !op.Done() {
+		t.Errorf("unexpected status %v", op.Status)
+	}
+}
+
+func TestDrive_CopyByID(t *testing.T) {
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1.0/drives/drive-id/items/dest-id":
+			fmt.Fprint(w, `{"id":"dest-id","folder":{},"parentReference":{"driveId":"drive-id"}}`)
+		case r.Method == "GET" && r.URL.Path == "/v1.0/drives/drive-id/items/file-id":
+			fmt.Fprint(w, `{"id":"file-id"}`)
+		case r.Method == "POST" && r.URL.Path == "/v1.0/drives/drive-id/items/src-id/copy":
+			w.Header().Set("Location", "http://"+r.Host+"/monitor/1")
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	drv := client.GetDrive("drive-id")
+
+	op, err := drv.CopyByID("src-id", "dest-id", "", drive.ConflictDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(op.MonitorURL, "/monitor/1") {
+		t.Errorf("unexpected monitor URL %v", op.MonitorURL)
+	}
+	if _, err := drv.CopyByID("src-id", "file-id", "", drive.ConflictDefault); err == nil {
+		t.Error("copy into a file did not fail")
+	}
+}
@@ -0,0 +1,149 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ClientCredentials is a TokenSource acquiring app-only tokens with the OAuth 2.0 client
+// credentials flow of the Microsoft identity platform (v2.0 endpoint).
+// See https://docs.microsoft.com/en-us/azure/active-directory/develop/v2-oauth2-client-creds-grant-flow
+//
+// The application authenticates with ClientSecret or, if Certificate is set, with a JWT client
+// assertion signed with PrivateKey, or, if Assertion is set, with the assertion it returns.
+//
+// If the v2.0 endpoint is not served by the login endpoint or rejects the scope derived from the
+// resource (e.g. "invalid_resource" or AADSTS500011), the token is requested from the legacy Azure AD
+// v1 endpoint instead. Set V1Endpoint to use the v1 endpoint only.
+type ClientCredentials struct {
+	TenantID      string
+	ApplicationID string
+	ClientSecret  string
+
+	Certificate *x509.Certificate // the certificate registered for the application, see NewGraphClientWithCertificate
+	PrivateKey  crypto.PrivateKey // the RSA key of Certificate
+
+	Assertion func(ctx context.Context) (string, error) // supplies a client assertion, e.g. a federated token, see WorkloadIdentity
+
+	LoginBaseURL string       // the login endpoint, defaults to the one of the Client the token is requested for
+	Resource     string       // the resource to get a token for, defaults to the one of the Client the token is requested for
+	HTTPClient   *http.Client // performs the token requests, defaults to the one of the Client the token is requested for
+	V1Endpoint   bool         // only use /{tenant}/oauth2/token with resource instead of /{tenant}/oauth2/v2.0/token with scope
+}
+
+// Token grab's a new token from the login endpoint
+func (cc *ClientCredentials) Token(ctx context.Context) (Token, error) {
+	if cc.TenantID == "" {
+		return Token{}, fmt.Errorf("tenant ID is empty")
+	}
+	token, err := cc.request(ctx, cc.V1Endpoint)
+	if err != nil && !cc.V1Endpoint && v1Fallback(err) {
+		token, err = cc.request(ctx, true)
+	}
+	if err != nil {
+		return Token{}, fmt.Errorf("error on getting msgraph Token: %v", err)
+	}
+	return token, nil
+}
+
+// request requests a token from the v2.0 endpoint, or the v1 endpoint if v1 is set
+func (cc *ClientCredentials) request(ctx context.Context, v1 bool) (Token, error) {
+	cli := requester(ctx, cc.HTTPClient)
+	loginBaseURL, resource := cc.LoginBaseURL, cc.Resource
+	if loginBaseURL == "" {
+		loginBaseURL = cli.loginURL()
+	}
+	if resource == "" {
+		resource = cli.tokenResource()
+	}
+
+	u, err := url.ParseRequestURI(loginBaseURL)
+	if err != nil {
+		return Token{}, fmt.Errorf("unable to parse URI: %v", err)
+	}
+	u.Path = tokenPath(cc.TenantID, v1)
+
+	data := url.Values{}
+	data.Add("grant_type", "client_credentials")
+	data.Add("client_id", cc.ApplicationID)
+	switch {
+	case cc.Assertion != nil:
+		assertion, err := cc.Assertion(ctx)
+		if err != nil {
+			return Token{}, err
+		}
+		data.Add("client_assertion_type", ClientAssertionType)
+		data.Add("client_assertion", assertion)
+	case cc.Certificate != nil:
+		assertion, err := clientAssertion(cc.ApplicationID, u.String(), cc.Certificate, cc.PrivateKey)
+		if err != nil {
+			return Token{}, err
+		}
+		data.Add("client_assertion_type", ClientAssertionType)
+		data.Add("client_assertion", assertion)
+	default:
+		data.Add("client_secret", cc.ClientSecret)
+	}
+	if v1 {
+		data.Add("resource", resource)
+	} else {
+		data.Add("scope", defaultScope(resource))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewBufferString(data.Encode()))
+
+	if err != nil {
+		return Token{}, fmt.Errorf("HTTP Request Error: %v", err)
+	}
+
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Content-Length", strconv.Itoa(len(data.Encode())))
+
+	var newToken Token
+	err = cli.performRequest(req, &newToken) // perform the prepared request
+	if err != nil {
+		return Token{}, err
+	}
+	return newToken, nil
+}
+
+// v1Fallback reports whether a token request failed at the v2.0 endpoint might succeed at the v1
+// endpoint: the authority does not serve the v2.0 endpoint (e.g. older Azure Stack or ADFS) or it
+// rejects the scope, as v2.0 scopes are not known for all resources. Failures of the credentials
+// themselves are not repeated.
+func v1Fallback(err error) bool {
+	re, ok := err.(*ReqError)
+	if !ok {
+		return false
+	}
+	if re.StatusCode == http.StatusNotFound {
+		return true
+	}
+	code, description := oauthError(err)
+	switch code {
+	case "invalid_resource", "invalid_scope":
+		return true
+	}
+	// AADSTS70011: invalid scope, AADSTS500011: resource principal not found
+	return strings.Contains(description, "AADSTS70011") || strings.Contains(description, "AADSTS500011")
+}
+
+// tokenPath returns the path of the token endpoint of tenant
+func tokenPath(tenant string, v1 bool) string {
+	if v1 {
+		return fmt.Sprintf("/%v/oauth2/token", tenant)
+	}
+	return fmt.Sprintf("/%v/oauth2/v2.0/token", tenant)
+}
+
+// defaultScope returns the scope requesting all permissions configured for the application on resource
+func defaultScope(resource string) string {
+	return strings.TrimSuffix(resource, "/") + "/.default"
+}
@@ -0,0 +1,284 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDelegatedScopes are requested by the delegated flows if no other scopes are configured.
+// offline_access is always requested in addition to get a refresh token.
+//
+// Scopes which are not qualified by a resource are permissions of the resource of DelegatedConfig, e.g.
+// "Files.ReadWrite.All" is requested as "https://graph.microsoft.us/Files.ReadWrite.All" in CloudUSGovernment.
+var DefaultDelegatedScopes = []string{"Files.ReadWrite.All", "User.Read"}
+
+// openIDScopes are not permissions of a resource and hence never qualified
+var openIDScopes = map[string]bool{"openid": true, "profile": true, "email": true, "offline_access": true}
+
+// DelegatedConfig identifies the application and the permissions it requests in the delegated
+// flows, hence the flows acquiring tokens on behalf of a signed-in user. Unlike app-only tokens
+// these can access personal OneDrives and /me/drive (see Client.GetMyDrive).
+type DelegatedConfig struct {
+	TenantID      string   // a tenant ID, "organizations", "consumers" or "common" (default)
+	ApplicationID string   // the application has to be registered for delegated permissions
+	ClientSecret  string   // only required for confidential (web) applications
+	Scopes        []string // the scopes to request, defaults to DefaultDelegatedScopes
+	Resource      string   // the resource unqualified scopes belong to, defaults to the one of the Client the token is requested for
+
+	LoginBaseURL string       // the login endpoint, defaults to the one of the Client the token is requested for
+	HTTPClient   *http.Client // performs the token requests, defaults to the one of the Client the token is requested for
+}
+
+// TokenSource returns a TokenSource which starts with token and acquires new tokens with its refresh token.
+// Refresh tokens returned along with new tokens replace the previous one.
+func (c DelegatedConfig) TokenSource(token Token) TokenSource {
+	return &refreshTokenSource{conf: c, token: token}
+}
+
+// tenant returns the tenant to sign in to
+func (c DelegatedConfig) tenant() string {
+	if c.TenantID == "" {
+		return "common"
+	}
+	return c.TenantID
+}
+
+// scope returns the scopes to request as space separated list, qualified by the resource
+func (c DelegatedConfig) scope(ctx context.Context) string {
+	scopes := c.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultDelegatedScopes
+	}
+	resource := c.Resource
+	if resource == "" {
+		resource = requester(ctx, c.HTTPClient).tokenResource()
+	}
+	resource = strings.TrimSuffix(resource, "/")
+
+	qualified := []string{"offline_access"}
+	for _, s := range scopes {
+		switch {
+		case s == "offline_access":
+			continue
+		case openIDScopes[s] || strings.Contains(s, "://"):
+			qualified = append(qualified, s)
+		default:
+			qualified = append(qualified, resource+"/"+s)
+		}
+	}
+	return strings.Join(qualified, " ")
+}
+
+// endpoint returns the URL of the v2.0 endpoint name (e.g. "token") of the login endpoint
+func (c DelegatedConfig) endpoint(ctx context.Context, name string) (string, error) {
+	loginBaseURL := c.LoginBaseURL
+	if loginBaseURL == "" {
+		loginBaseURL = requester(ctx, c.HTTPClient).loginURL()
+	}
+	u, err := url.ParseRequestURI(loginBaseURL)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse URI: %v", err)
+	}
+	u.Path = fmt.Sprintf("/%v/oauth2/v2.0/%v", c.tenant(), name)
+	return u.String(), nil
+}
+
+// requestToken posts data to the token endpoint
+func (c DelegatedConfig) requestToken(ctx context.Context, data url.Values) (Token, error) {
+	endpoint, err := c.endpoint(ctx, "token")
+	if err != nil {
+		return Token{}, err
+	}
+	data.Set("client_id", c.ApplicationID)
+	if c.ClientSecret != "" {
+		data.Set("client_secret", c.ClientSecret)
+	}
+	data.Set("scope", c.scope(ctx))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBufferString(data.Encode()))
+	if err != nil {
+		return Token{}, fmt.Errorf("HTTP Request Error: %v", err)
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Content-Length", strconv.Itoa(len(data.Encode())))
+
+	var newToken Token
+	err = requester(ctx, c.HTTPClient).performRequest(req, &newToken)
+	if err != nil {
+		return Token{}, err
+	}
+	return newToken, nil
+}
+
+// refreshTokenSource acquires tokens with the refresh token of the previous token
+type refreshTokenSource struct {
+	conf DelegatedConfig
+
+	mu    sync.Mutex
+	token Token
+}
+
+// Token returns the current token if it is still valid, otherwise it is refreshed
+func (rs *refreshTokenSource) Token(ctx context.Context) (Token, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if !rs.token.WantsToBeRefreshed() {
+		return rs.token, nil
+	}
+	if rs.token.RefreshToken == "" {
+		return Token{}, fmt.Errorf("token has expired and has no refresh token")
+	}
+
+	data := url.Values{}
+	data.Add("grant_type", "refresh_token")
+	data.Add("refresh_token", rs.token.RefreshToken)
+	newToken, err := rs.conf.requestToken(ctx, data)
+	if err != nil {
+		return Token{}, fmt.Errorf("error on refreshing msgraph Token: %v", err)
+	}
+	if newToken.RefreshToken == "" { // keep using the previous one
+		newToken.RefreshToken = rs.token.RefreshToken
+	}
+	rs.token = newToken
+	return newToken, nil
+}
+
+// AuthCodeFlow acquires delegated tokens with the OAuth 2.0 authorization code flow and PKCE.
+// See https://docs.microsoft.com/en-us/azure/active-directory/develop/v2-oauth2-auth-code-flow
+//
+// The user is sent to the login page (see LoginHandler or AuthCodeURL) and redirected back to
+// RedirectURL after consenting, the handler of RedirectURL (see CallbackHandler or Exchange)
+// then exchanges the authorization code for a token.
+type AuthCodeFlow struct {
+	DelegatedConfig
+	RedirectURL string // has to be registered as redirect URI of the application
+
+	mu      sync.Mutex
+	pending map[string]pendingAuth // by state
+}
+
+// pendingAuth is an authorization which has been started but not yet completed
+type pendingAuth struct {
+	verifier string // PKCE code verifier
+	started  time.Time
+}
+
+// authTimeout is the time the user has to complete an authorization
+const authTimeout = 10 * time.Minute
+
+// AuthCodeURL starts a new authorization and returns the URL of the login page together with
+// the state identifying the authorization. The state has to be passed to Exchange.
+func (f *AuthCodeFlow) AuthCodeURL() (authURL, state string, err error) {
+	state, err = randomString(16)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	ctx := context.Background()
+	endpoint, err := f.endpoint(ctx, "authorize")
+	if err != nil {
+		return "", "", err
+	}
+
+	f.mu.Lock()
+	if f.pending == nil {
+		f.pending = map[string]pendingAuth{}
+	}
+	for s, p := range f.pending { // forget authorizations which have not been completed in time
+		if time.Since(p.started) > authTimeout {
+			delete(f.pending, s)
+		}
+	}
+	f.pending[state] = pendingAuth{verifier: verifier, started: time.Now()}
+	f.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{}
+	query.Add("client_id", f.ApplicationID)
+	query.Add("response_type", "code")
+	query.Add("redirect_uri", f.RedirectURL)
+	query.Add("response_mode", "query")
+	query.Add("scope", f.scope(ctx))
+	query.Add("state", state)
+	query.Add("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Add("code_challenge_method", "S256")
+	return endpoint + "?" + query.Encode(), state, nil
+}
+
+// Exchange completes the authorization identified by state and exchanges code for a token.
+func (f *AuthCodeFlow) Exchange(ctx context.Context, state, code string) (Token, error) {
+	f.mu.Lock()
+	p, ok := f.pending[state]
+	delete(f.pending, state)
+	f.mu.Unlock()
+	if !ok || time.Since(p.started) > authTimeout {
+		return Token{}, fmt.Errorf("unknown or expired authorization state")
+	}
+
+	data := url.Values{}
+	data.Add("grant_type", "authorization_code")
+	data.Add("code", code)
+	data.Add("redirect_uri", f.RedirectURL)
+	data.Add("code_verifier", p.verifier)
+	token, err := f.requestToken(ctx, data)
+	if err != nil {
+		return Token{}, fmt.Errorf("error on redeeming authorization code: %v", err)
+	}
+	return token, nil
+}
+
+// LoginHandler returns an http.Handler which starts a new authorization and redirects to the login page.
+func (f *AuthCodeFlow) LoginHandler() http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		authURL, _, err := f.AuthCodeURL()
+		if err != nil {
+			http.Error(resp, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(resp, req, authURL, http.StatusFound)
+	})
+}
+
+// CallbackHandler returns the http.Handler of RedirectURL. It completes the authorization and passes
+// the token to onToken, which is expected to store it, e.g. by creating a Client with TokenSource.
+func (f *AuthCodeFlow) CallbackHandler(onToken func(ctx context.Context, token Token) error) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		if e := query.Get("error"); e != "" {
+			http.Error(resp, fmt.Sprintf("authorization failed: %v: %v", e, query.Get("error_description")), http.StatusBadRequest)
+			return
+		}
+		token, err := f.Exchange(req.Context(), query.Get("state"), query.Get("code"))
+		if err != nil {
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := onToken(req.Context(), token); err != nil {
+			http.Error(resp, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp.Write([]byte("Login succeeded."))
+	})
+}
+
+// randomString returns n random bytes encoded as unpadded base64url
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
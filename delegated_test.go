@@ -0,0 +1,140 @@
+package drive_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+// newFakeLogin serves the v2.0 token endpoint of the "common" tenant. Every token carries a new
+// refresh token, grant checks the form of each token request.
+func newFakeLogin(t *testing.T, grant func(form url.Values) bool) *httptest.Server {
+	issued := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/common/oauth2/v2.0/token" {
+			http.NotFound(w, r)
+			return
+		}
+		r.ParseForm()
+		if !grant(r.PostForm) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		issued++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token_type":    "Bearer",
+			"expires_in":    3599,
+			"scope":         r.PostForm.Get("scope"),
+			"access_token":  "access-" + strconv.Itoa(issued),
+			"refresh_token": "refresh-" + strconv.Itoa(issued),
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAuthCodeFlow(t *testing.T) {
+	var challenge string
+	srv := newFakeLogin(t, func(form url.Values) bool {
+		switch form.Get("grant_type") {
+		case "authorization_code":
+			sum := sha256.Sum256([]byte(form.Get("code_verifier")))
+			return form.Get("code") == "the-code" && base64.RawURLEncoding.EncodeToString(sum[:]) == challenge
+		case "refresh_token":
+			return form.Get("refresh_token") == "refresh-1"
+		}
+		return false
+	})
+	flow := &drive.AuthCodeFlow{
+		DelegatedConfig: drive.DelegatedConfig{ApplicationID: "application", LoginBaseURL: srv.URL},
+		RedirectURL:     "http://localhost/callback",
+	}
+
+	authURL, state, err := flow.AuthCodeURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	challenge = query.Get("code_challenge")
+	if u.Path != "/common/oauth2/v2.0/authorize" || query.Get("code_challenge_method") != "S256" || query.Get("state") != state {
+		t.Errorf("unexpected authorization URL %v", authURL)
+	}
+
+	// the redirect handler completes the flow
+	var token drive.Token
+	callback := flow.CallbackHandler(func(ctx context.Context, t drive.Token) error {
+		token = t
+		return nil
+	})
+	rec := httptest.NewRecorder()
+	callback.ServeHTTP(rec, httptest.NewRequest("GET", "/callback?code=the-code&state="+state, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("callback failed: %v", rec.Body.String())
+	}
+	if token.AccessToken != "access-1" || token.RefreshToken != "refresh-1" || len(token.Scopes) == 0 {
+		t.Errorf("unexpected token %+v", token)
+	}
+	if _, err := flow.Exchange(context.Background(), state, "the-code"); err == nil {
+		t.Error("state could be used twice")
+	}
+
+	// the refresh token is used once the token expired
+	token.ExpiresOn = token.NotBefore
+	src := flow.TokenSource(token)
+	refreshed, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.AccessToken != "access-2" || refreshed.RefreshToken != "refresh-2" {
+		t.Errorf("unexpected refreshed token %+v", refreshed)
+	}
+}
+
+func TestDelegatedConfig_Scopes(t *testing.T) {
+	// unqualified scopes are permissions of the msgraph of the configured cloud
+	flow := &drive.AuthCodeFlow{
+		DelegatedConfig: drive.DelegatedConfig{ApplicationID: "application", Resource: drive.CloudUSGovernment.BaseURL,
+			Scopes: []string{"openid", "Files.Read", "https://example.com/Custom.Read"}},
+		RedirectURL: "http://localhost/callback",
+	}
+	authURL, _, err := flow.AuthCodeURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "offline_access openid https://graph.microsoft.us/Files.Read https://example.com/Custom.Read"
+	if scope := u.Query().Get("scope"); scope != expected {
+		t.Errorf("scope = %q, expected %q", scope, expected)
+	}
+
+	// without Resource the scopes belong to the cloud of the Client the token is requested for
+	var scope string
+	srv := newFakeLogin(t, func(form url.Values) bool {
+		scope = form.Get("scope")
+		return form.Get("refresh_token") == "refresh-0"
+	})
+	conf := drive.DelegatedConfig{ApplicationID: "application", LoginBaseURL: srv.URL}
+	_, err = drive.NewClient(conf.TokenSource(drive.Token{RefreshToken: "refresh-0"}), drive.WithCloud(drive.CloudUSGovernment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "offline_access https://graph.microsoft.us/Files.ReadWrite.All https://graph.microsoft.us/User.Read"; scope != expected {
+		t.Errorf("scope = %q, expected %q", scope, expected)
+	}
+}
@@ -0,0 +1,50 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+)
+
+// Delete deletes the item at path, folders are deleted along with their content. If ifMatch is not
+// empty, the item is only deleted if its ETag (see Item.ETag) still matches, otherwise an error
+// matching ErrPreconditionFailed is returned.
+func (drv *Drive) Delete(path, ifMatch string) error {
+	return drv.DeleteContext(context.Background(), path, ifMatch)
+}
+
+// DeleteContext is like Delete but performs the API-call with ctx.
+func (drv *Drive) DeleteContext(ctx context.Context, path, ifMatch string) error {
+	if isRoot(path) {
+		return fmt.Errorf("the root folder can not be deleted")
+	}
+	return drv.delete(ctx, drv.itemSource(path), ifMatch)
+}
+
+// DeleteByID is like Delete but addresses the item by its ID.
+func (drv *Drive) DeleteByID(id, ifMatch string) error {
+	return drv.DeleteByIDContext(context.Background(), id, ifMatch)
+}
+
+// DeleteByIDContext is like DeleteByID but performs the API-call with ctx.
+func (drv *Drive) DeleteByIDContext(ctx context.Context, id, ifMatch string) error {
+	if id == "" {
+		return fmt.Errorf("item ID is empty")
+	}
+	return drv.delete(ctx, drv.idSource(id), ifMatch)
+}
+
+// delete deletes the item of the API-call source
+func (drv *Drive) delete(ctx context.Context, source, ifMatch string) error {
+	reqURL, err := drv.Client.apiURL(source, nil)
+	if err != nil {
+		return err
+	}
+	req, err := drv.Client.newRequest(ctx, "DELETE", reqURL, nil)
+	if err != nil {
+		return err
+	}
+	if ifMatch != "" {
+		req.Header.Add("If-Match", ifMatch)
+	}
+	return drv.Client.performRequest(req, nil)
+}
@@ -0,0 +1,54 @@
+package drive_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+func TestDrive_Delete(t *testing.T) {
+	var deleted []string
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET":
+			fmt.Fprint(w, `{"id":"file-id","eTag":"\"{file-id},2\"","cTag":"\"c:{file-id},2\""}`)
+		case r.Method != "DELETE":
+			http.NotFound(w, r)
+		case r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != `"{file-id},2"`:
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprint(w, `{"error":{"code":"resourceModified"}}`)
+		default:
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	drv := client.GetDrive("drive-id")
+
+	item, err := drv.Item("/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ETag != `"{file-id},2"` || item.CTag != `"c:{file-id},2"` {
+		t.Errorf("unexpected tags %q, %q", item.ETag, item.CTag)
+	}
+
+	err = drv.Delete("/file", `"{file-id},1"`)
+	if !errors.Is(err, drive.ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed, got %v", err)
+	}
+	if err := drv.Delete("/file", item.ETag); err != nil {
+		t.Fatal(err)
+	}
+	if err := drv.DeleteByID("file-id", ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 2 || deleted[0] != "/v1.0/drives/drive-id/root:/file" || deleted[1] != "/v1.0/drives/drive-id/items/file-id" {
+		t.Errorf("unexpected deletions %v", deleted)
+	}
+	if err := drv.Delete("/", ""); err == nil {
+		t.Error("root folder could be deleted")
+	}
+}
@@ -0,0 +1,133 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DeviceCodeGrantType is the grant_type of a token request of the device code flow
+const DeviceCodeGrantType string = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DeviceCodeFlow acquires delegated tokens with the OAuth 2.0 device authorization grant, hence
+// without a browser on the machine requesting the token.
+// See https://docs.microsoft.com/en-us/azure/active-directory/develop/v2-oauth2-device-code
+//
+// Start requests a user code, the user enters it at the verification URL on any other device and
+// signs in. Meanwhile Wait polls the token endpoint until the sign in completed.
+type DeviceCodeFlow struct {
+	DelegatedConfig
+}
+
+// DeviceCode is a pending device authorization returned by DeviceCodeFlow.Start
+type DeviceCode struct {
+	UserCode        string        // the code the user has to enter at VerificationURL
+	DeviceCode      string        // identifies the authorization when polling the token endpoint
+	VerificationURL string        // the URL the user has to visit
+	Message         string        // human readable instructions containing UserCode and VerificationURL
+	ExpiresOn       time.Time     // time when DeviceCode expires
+	Interval        time.Duration // time to wait between two polls of the token endpoint
+}
+
+// defaultPollInterval is used if the login endpoint does not report an interval
+const defaultPollInterval = 5 * time.Second
+
+// Start requests a new device code. Message of the returned DeviceCode has to be shown to the user.
+func (f *DeviceCodeFlow) Start(ctx context.Context) (*DeviceCode, error) {
+	endpoint, err := f.endpoint(ctx, "devicecode")
+	if err != nil {
+		return nil, err
+	}
+	data := url.Values{}
+	data.Add("client_id", f.ApplicationID)
+	data.Add("scope", f.scope(ctx))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBufferString(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("HTTP Request Error: %v", err)
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Content-Length", strconv.Itoa(len(data.Encode())))
+
+	tmp := struct {
+		UserCode        string  `json:"user_code"`
+		DeviceCode      string  `json:"device_code"`
+		VerificationURI string  `json:"verification_uri"`
+		Message         string  `json:"message"`
+		ExpiresIn       flexInt `json:"expires_in"`
+		Interval        flexInt `json:"interval"`
+	}{}
+	if err := requester(ctx, f.HTTPClient).performRequest(req, &tmp); err != nil {
+		return nil, fmt.Errorf("error on requesting device code: %v", err)
+	}
+	dc := &DeviceCode{
+		UserCode:        tmp.UserCode,
+		DeviceCode:      tmp.DeviceCode,
+		VerificationURL: tmp.VerificationURI,
+		Message:         tmp.Message,
+		ExpiresOn:       time.Now().Add(time.Duration(tmp.ExpiresIn) * time.Second),
+		Interval:        time.Duration(tmp.Interval) * time.Second,
+	}
+	if dc.Interval <= 0 {
+		dc.Interval = defaultPollInterval
+	}
+	if dc.Message == "" {
+		dc.Message = fmt.Sprintf("To sign in, open %v and enter the code %v.", dc.VerificationURL, dc.UserCode)
+	}
+	return dc, nil
+}
+
+// Wait polls the token endpoint until the user completed the sign in of dc and returns the token.
+// It fails if the user declined, dc expired or ctx is done.
+func (f *DeviceCodeFlow) Wait(ctx context.Context, dc *DeviceCode) (Token, error) {
+	interval := dc.Interval
+	for {
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return Token{}, ctx.Err()
+		case <-timer.C:
+		}
+
+		data := url.Values{}
+		data.Add("grant_type", DeviceCodeGrantType)
+		data.Add("device_code", dc.DeviceCode)
+		token, err := f.requestToken(ctx, data)
+		if err == nil {
+			return token, nil
+		}
+		switch code, description := oauthError(err); code {
+		case "authorization_pending": // the user has not signed in yet
+		case "slow_down": // see https://tools.ietf.org/html/rfc8628#section-3.5
+			interval += 5 * time.Second
+		case "":
+			return Token{}, fmt.Errorf("error on polling device code: %v", err)
+		default: // authorization_declined, expired_token, bad_verification_code
+			return Token{}, fmt.Errorf("device code login failed: %v: %v", code, description)
+		}
+		if time.Now().After(dc.ExpiresOn) {
+			return Token{}, fmt.Errorf("device code login failed: device code expired")
+		}
+	}
+}
+
+// Login runs the whole flow: it starts a new device authorization, passes it to prompt, which is
+// expected to show the Message to the user, and waits for the token.
+func (f *DeviceCodeFlow) Login(ctx context.Context, prompt func(dc *DeviceCode)) (Token, error) {
+	dc, err := f.Start(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+	prompt(dc)
+	return f.Wait(ctx, dc)
+}
+
+// String returns the instructions for the user
+func (dc *DeviceCode) String() string {
+	return dc.Message
+}
@@ -0,0 +1,81 @@
+package drive_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+func TestDeviceCodeFlow(t *testing.T) {
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/common/oauth2/v2.0/devicecode":
+			if !strings.Contains(r.PostForm.Get("scope"), "offline_access") {
+				t.Errorf("offline_access not requested: %v", r.PostForm)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"user_code":        "USER-CODE",
+				"device_code":      "device-code",
+				"verification_uri": "https://microsoft.com/devicelogin",
+				"expires_in":       900,
+				"interval":         5,
+			})
+		case "/common/oauth2/v2.0/token":
+			if r.PostForm.Get("grant_type") != drive.DeviceCodeGrantType || r.PostForm.Get("device_code") != "device-code" {
+				t.Errorf("unexpected form %v", r.PostForm)
+			}
+			polls++
+			if polls < 3 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"token_type":    "Bearer",
+				"expires_in":    3599,
+				"access_token":  "device-token",
+				"refresh_token": "device-refresh",
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	flow := &drive.DeviceCodeFlow{DelegatedConfig: drive.DelegatedConfig{ApplicationID: "application", LoginBaseURL: srv.URL}}
+	token, err := flow.Login(context.Background(), func(dc *drive.DeviceCode) {
+		if dc.UserCode != "USER-CODE" || dc.Interval != 5*time.Second || !strings.Contains(dc.Message, "USER-CODE") {
+			t.Errorf("unexpected device code %+v", dc)
+		}
+		dc.Interval = time.Millisecond // do not slow down the test
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polls != 3 || token.AccessToken != "device-token" || token.RefreshToken != "device-refresh" {
+		t.Errorf("unexpected token %+v after %d polls", token, polls)
+	}
+}
+
+func TestDeviceCodeFlow_Declined(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "authorization_declined"})
+	}))
+	defer srv.Close()
+
+	flow := &drive.DeviceCodeFlow{DelegatedConfig: drive.DelegatedConfig{ApplicationID: "application", LoginBaseURL: srv.URL}}
+	dc := &drive.DeviceCode{DeviceCode: "device-code", ExpiresOn: time.Now().Add(time.Minute), Interval: time.Millisecond}
+	_, err := flow.Wait(context.Background(), dc)
+	if err == nil || !strings.Contains(err.Error(), "authorization_declined") {
+		t.Errorf("expected declined error, got %v", err)
+	}
+}
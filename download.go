@@ -0,0 +1,243 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// ItemReader reads the content of a file without buffering it, see Drive.Open. Every (re)positioned
+// read issues a single Range request, which is then read sequentially.
+//
+// The content is read from the pre-authenticated download URL of the item, an expired URL is
+// refetched transparently. Items without download URL are read from their /content endpoint.
+type ItemReader struct {
+	ctx    context.Context
+	drv    *Drive
+	item   *Item
+	offset int64
+	body   io.ReadCloser // the response currently read, nil if none is open
+	closed bool
+}
+
+var _ io.ReadSeekCloser = (*ItemReader)(nil)
+
+// Open opens the file at path for reading. No content is requested until the first Read.
+func (drv *Drive) Open(path string) (*ItemReader, error) {
+	return drv.OpenContext(context.Background(), path)
+}
+
+// OpenContext is like Open but performs all API-calls and requests of the reader with ctx.
+func (drv *Drive) OpenContext(ctx context.Context, path string) (*ItemReader, error) {
+	item, err := drv.ItemContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if item.IsFolder() {
+		return nil, fmt.Errorf("%v is a folder", path)
+	}
+	return &ItemReader{ctx: ctx, drv: drv, item: item}, nil
+}
+
+// OpenByID is like Open but addresses the file by its ID.
+func (drv *Drive) OpenByID(id string) (*ItemReader, error) {
+	return drv.OpenByIDContext(context.Background(), id)
+}
+
+// OpenByIDContext is like OpenByID but performs all API-calls and requests of the reader with ctx.
+func (drv *Drive) OpenByIDContext(ctx context.Context, id string) (*ItemReader, error) {
+	item, err := drv.ItemByIDContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.IsFolder() {
+		return nil, fmt.Errorf("%v is a folder", item.Name)
+	}
+	return &ItemReader{ctx: ctx, drv: drv, item: item}, nil
+}
+
+// Item returns the item read, its DownloadURL is updated whenever it is refetched
+func (r *ItemReader) Item() *Item {
+	return r.item
+}
+
+// Size returns the size of the file
+func (r *ItemReader) Size() int64 {
+	return r.item.Size
+}
+
+// Read implements io.Reader
+func (r *ItemReader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, errors.New("read of closed ItemReader")
+	}
+	if r.offset >= r.item.Size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		body, err := r.open()
+		if err != nil {
+			return 0, err
+		}
+		r.body = body
+	}
+
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	if err == io.EOF {
+		r.body.Close()
+		r.body = nil
+		switch {
+		case r.offset >= r.item.Size:
+			return n, io.EOF
+		case n == 0:
+			return 0, io.ErrUnexpectedEOF
+		default: // the next Read continues with a new request
+			return n, nil
+		}
+	}
+	return n, err
+}
+
+// Seek implements io.Seeker. Seeking does not perform any request.
+func (r *ItemReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.item.Size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	if offset != r.offset && r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// Close implements io.Closer
+func (r *ItemReader) Close() error {
+	r.closed = true
+	if r.body != nil {
+		err := r.body.Close()
+		r.body = nil
+		return err
+	}
+	return nil
+}
+
+// open requests the content starting at the current offset
+func (r *ItemReader) open() (io.ReadCloser, error) {
+	body, err := r.drv.requestContent(r.ctx, r.item.ID, r.item.DownloadURL, r.offset, -1)
+	if r.item.DownloadURL == "" || !(isExpired(err) || isNotFound(err)) {
+		return body, err
+	}
+
+	// download URLs are only valid for a short time, get a new one
+	downloadURL, refetchErr := r.drv.downloadURL(r.ctx, r.item.ID)
+	if refetchErr != nil {
+		return nil, refetchFailed(err, refetchErr)
+	}
+	r.item.DownloadURL = downloadURL
+	return r.drv.requestContent(r.ctx, r.item.ID, downloadURL, r.offset, -1)
+}
+
+// downloadURL fetches a new download URL of the item with id
+func (drv *Drive) downloadURL(ctx context.Context, id string) (string, error) {
+	item := &Item{}
+	if err := drv.Client.makeGETAPICall(ctx, drv.idSource(id), nil, item); err != nil {
+		return "", err
+	}
+	return item.DownloadURL, nil
+}
+
+// requestContent requests the bytes start to end (inclusive, -1 for the rest of the file) of the item
+// with id from the pre-authenticated downloadURL, or from the /content endpoint if downloadURL is empty.
+func (drv *Drive) requestContent(ctx context.Context, id, downloadURL string, start, end int64) (io.ReadCloser, error) {
+	cli := drv.Client
+	var req *http.Request
+	var err error
+	if downloadURL == "" {
+		var reqURL string
+		if reqURL, err = cli.apiURL(drv.idSource(id)+"/content", nil); err != nil {
+			return nil, err
+		}
+		req, err = cli.newRequest(ctx, "GET", reqURL, nil)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, "GET", downloadURL, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if end >= 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	} else {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", start))
+	}
+
+	resp, err := cli.streaming().do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusPartialContent && start > 0 {
+		// the Range header has been ignored, skip to the start
+		if _, err := io.CopyN(ioutil.Discard, resp.Body, start); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("HTTP response read error: %v of http.Request: %v", err, req.URL)
+		}
+	}
+	if resp.StatusCode != http.StatusPartialContent && end >= 0 {
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(resp.Body, end-start+1), resp.Body}, nil
+	}
+	return resp.Body, nil
+}
+
+// isExpired reports whether err has been caused by an expired download URL
+func isExpired(err error) bool {
+	re, ok := err.(*ReqError)
+	if !ok {
+		return false
+	}
+	switch re.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusGone:
+		return true
+	}
+	return false
+}
+
+// isNotFound reports whether err is a 404. Expired download URLs are sometimes reported as 404,
+// which is only known once the item turned out to still exist.
+func isNotFound(err error) bool {
+	re, ok := err.(*ReqError)
+	return ok && re.StatusCode == http.StatusNotFound
+}
+
+// refetchFailed returns the error to report if the download URL could not be refetched after err.
+// A 404 is reported as is, as the item has most likely been deleted.
+func refetchFailed(err, refetchErr error) error {
+	if isNotFound(err) {
+		return err
+	}
+	return refetchErr
+}
+
+// streaming returns a Client performing requests like cli but without the time limit of a
+// single request, which would otherwise interrupt reading large response bodies. Requests are
+// still bound to their context.
+func (cli *Client) streaming() *Client {
+	c := cli.copyHTTPClient()
+	c.Timeout = 0
+	return &Client{httpClient: c, userAgent: cli.userAgent, retry: cli.retry}
+}
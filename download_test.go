@@ -0,0 +1,135 @@
+package drive_test
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+func TestDrive_Open(t *testing.T) {
+	const content = "0123456789abcdefghij"
+	var itemCalls, expiredCalls int
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.0/drives/drive-id/root:/file.bin", "/v1.0/drives/drive-id/items/file-id":
+			itemCalls++
+			fmt.Fprintf(w, `{"id":"file-id","size":%d,"@microsoft.graph.downloadUrl":"http://%s/download/%d"}`, len(content), r.Host, itemCalls)
+		case "/download/1": // the URL of the first call has expired
+			expiredCalls++
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/download/2":
+			if r.Header.Get("Authorization") != "" {
+				t.Error("Authorization header sent to the download URL")
+			}
+			var start int
+			fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start)
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			io.WriteString(w, content[start:])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	r, err := client.GetDrive("drive-id").Open("/file.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := r.Seek(-5, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	tail, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(tail) != "fghij" || itemCalls != 2 || expiredCalls != 1 {
+		t.Errorf("read %q with %d item calls and %d expired calls", tail, itemCalls, expiredCalls)
+	}
+
+	r.Seek(10, io.SeekStart)
+	buf := make([]byte, 3)
+	if _, err := io.ReadFull(r, buf); err != nil || string(buf) != "abc" {
+		t.Errorf("read %q, error %v", buf, err)
+	}
+	r.Seek(0, io.SeekStart)
+	all, err := ioutil.ReadAll(r)
+	if err != nil || string(all) != content {
+		t.Errorf("read %q, error %v", all, err)
+	}
+}
+
+func TestDrive_OpenByID(t *testing.T) {
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.0/drives/drive-id/items/file-id":
+			fmt.Fprint(w, `{"id":"file-id","size":7}`)
+		case "/v1.0/drives/drive-id/items/folder-id":
+			fmt.Fprint(w, `{"id":"folder-id","name":"folder","folder":{}}`)
+		case "/v1.0/drives/drive-id/items/file-id/content":
+			io.WriteString(w, "content")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	drv := client.GetDrive("drive-id")
+
+	r, err := drv.OpenByID("file-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if all, err := ioutil.ReadAll(r); err != nil || string(all) != "content" {
+		t.Errorf("read %q, error %v", all, err)
+	}
+	if _, err := drv.OpenByID("folder-id"); err == nil {
+		t.Error("opening a folder did not fail")
+	}
+}
+
+func TestDrive_OpenNotFound(t *testing.T) {
+	var itemCalls int
+	var deleted bool
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.0/drives/drive-id/items/file-id":
+			if deleted {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"error":{"code":"accessDenied"}}`)
+				return
+			}
+			itemCalls++
+			fmt.Fprintf(w, `{"id":"file-id","size":7,"@microsoft.graph.downloadUrl":"http://%s/download/%d"}`, r.Host, itemCalls)
+		case "/download/2":
+			io.WriteString(w, "content")
+		default: // the first download URL is reported as not found
+			http.NotFound(w, r)
+		}
+	}))
+	drv := client.GetDrive("drive-id")
+
+	// the item still exists, hence the download URL expired
+	r, err := drv.OpenByID("file-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all, err := ioutil.ReadAll(r); err != nil || string(all) != "content" || itemCalls != 2 {
+		t.Errorf("read %q with %d item calls, error %v", all, itemCalls, err)
+	}
+
+	// the item can not be fetched anymore, the 404 of the download URL is reported
+	itemCalls = 0
+	r, err = drv.OpenByID("file-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted = true
+	_, err = ioutil.ReadAll(r)
+	if re, ok := err.(*drive.ReqError); !ok || re.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the 404 of the download URL, got %v", err)
+	}
+}
@@ -0,0 +1,294 @@
+package drive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// DownloadStateSuffix is appended to the path of a local file to get the path of the file recording
+// the progress of Drive.Download
+const DownloadStateSuffix string = ".download"
+
+// DefaultDownloadWorkers is the number of ranges Download fetches concurrently if no other has been set
+const DefaultDownloadWorkers int = 4
+
+// DefaultDownloadRangeSize is the size of the ranges of Download if no other has been set
+const DefaultDownloadRangeSize int64 = 8 * 1024 * 1024
+
+// DownloadOptions configures Download
+type DownloadOptions struct {
+	Workers   int                          // number of ranges fetched concurrently, defaults to DefaultDownloadWorkers
+	RangeSize int64                        // size of a range, defaults to DefaultDownloadRangeSize
+	Progress  func(downloaded, size int64) // called after every range
+	NoVerify  bool                         // do not verify the content against the hashes of the item, see Hashes.Verify
+}
+
+// downloadState is the content of the state file of Download
+type downloadState struct {
+	ID           string    `json:"id"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	RangeSize    int64     `json:"rangeSize"`
+	Done         []bool    `json:"done"` // by range
+}
+
+// matches reports whether state belongs to a download of item with the given range size
+func (state *downloadState) matches(item *Item, rangeSize int64) bool {
+	return state.ID == item.ID && state.Size == item.Size && state.LastModified.Equal(item.LastMod) &&
+		state.RangeSize == rangeSize && int64(len(state.Done)) == ranges(item.Size, rangeSize)
+}
+
+// ranges returns the number of ranges of rangeSize a file of size consists of
+func ranges(size, rangeSize int64) int64 {
+	return (size + rangeSize - 1) / rangeSize
+}
+
+// Download downloads the file at path to the local file localPath. The file is split into ranges which
+// are fetched concurrently and written in place. The completed ranges are recorded in a state file
+// next to localPath (see DownloadStateSuffix), hence an interrupted download resumes where it stopped
+// as long as the remote file has not changed. Finally the content is verified against the hashes of
+// the item and the state file is removed, a corrupted download is reported as *HashMismatchError.
+// opts may be nil.
+func (drv *Drive) Download(path, localPath string, opts *DownloadOptions) (*Item, error) {
+	return drv.DownloadContext(context.Background(), path, localPath, opts)
+}
+
+// DownloadContext is like Download but performs the API-calls and requests with ctx.
+func (drv *Drive) DownloadContext(ctx context.Context, path, localPath string, opts *DownloadOptions) (*Item, error) {
+	item, err := drv.ItemContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if item.IsFolder() {
+		return nil, fmt.Errorf("%v is a folder", path)
+	}
+	return drv.downloadItem(ctx, item, localPath, opts)
+}
+
+// DownloadByID is like Download but addresses the file by its ID.
+func (drv *Drive) DownloadByID(id, localPath string, opts *DownloadOptions) (*Item, error) {
+	return drv.DownloadByIDContext(context.Background(), id, localPath, opts)
+}
+
+// DownloadByIDContext is like DownloadByID but performs the API-calls and requests with ctx.
+func (drv *Drive) DownloadByIDContext(ctx context.Context, id, localPath string, opts *DownloadOptions) (*Item, error) {
+	item, err := drv.ItemByIDContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.IsFolder() {
+		return nil, fmt.Errorf("%v is a folder", item.Name)
+	}
+	return drv.downloadItem(ctx, item, localPath, opts)
+}
+
+// downloadItem downloads the file item to localPath, see Download
+func (drv *Drive) downloadItem(ctx context.Context, item *Item, localPath string, opts *DownloadOptions) (*Item, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	workers, rangeSize := opts.Workers, opts.RangeSize
+	if workers <= 0 {
+		workers = DefaultDownloadWorkers
+	}
+	if rangeSize <= 0 {
+		rangeSize = DefaultDownloadRangeSize
+	}
+
+	statePath := localPath + DownloadStateSuffix
+	state := &downloadState{}
+	if data, err := ioutil.ReadFile(statePath); err != nil || json.Unmarshal(data, state) != nil || !state.matches(item, rangeSize) {
+		state = &downloadState{
+			ID:           item.ID,
+			Size:         item.Size,
+			LastModified: item.LastMod,
+			RangeSize:    rangeSize,
+			Done:         make([]bool, ranges(item.Size, rangeSize)),
+		}
+		os.Remove(localPath) // the content is stale
+	}
+
+	f, err := os.OpenFile(localPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := f.Truncate(item.Size); err != nil {
+		return nil, err
+	}
+
+	d := &download{drv: drv, item: item, file: f, state: state, statePath: statePath, progress: opts.Progress}
+	if err := d.run(ctx, workers); err != nil {
+		return nil, err
+	}
+	if err := f.Sync(); err != nil {
+		return nil, err
+	}
+
+	if !opts.NoVerify && item.File != nil {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := item.verify(f); err != nil {
+			os.Remove(statePath) // start over next time
+			return nil, err
+		}
+	}
+	os.Remove(statePath)
+	return item, nil
+}
+
+// download is a Download in progress
+type download struct {
+	drv       *Drive
+	item      *Item
+	file      *os.File
+	statePath string
+	progress  func(downloaded, size int64)
+
+	mu          sync.Mutex // protects the fields below
+	state       *downloadState
+	downloadURL string // refetched once it expired
+}
+
+// run fetches all missing ranges with the given number of workers
+func (d *download) run(ctx context.Context, workers int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d.downloadURL = d.item.DownloadURL
+
+	jobs := make(chan int64)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				if err := d.fetch(ctx, idx); err != nil {
+					errs <- err
+					cancel() // stop the other workers, the completed ranges are kept
+					return
+				}
+			}
+		}()
+	}
+feed:
+	for idx, done := range d.state.Done {
+		if done {
+			continue
+		}
+		select {
+		case jobs <- int64(idx):
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// fetch downloads the range idx, writes it to the file and records it in the state file
+func (d *download) fetch(ctx context.Context, idx int64) error {
+	start := idx * d.state.RangeSize
+	end := start + d.state.RangeSize - 1
+	if end >= d.item.Size {
+		end = d.item.Size - 1
+	}
+
+	d.mu.Lock()
+	downloadURL := d.downloadURL
+	d.mu.Unlock()
+	body, err := d.drv.requestContent(ctx, d.item.ID, downloadURL, start, end)
+	if downloadURL != "" && (isExpired(err) || isNotFound(err)) {
+		var refetchErr error
+		if downloadURL, refetchErr = d.refreshURL(ctx, downloadURL); refetchErr != nil {
+			return refetchFailed(err, refetchErr)
+		}
+		body, err = d.drv.requestContent(ctx, d.item.ID, downloadURL, start, end)
+	}
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	n, err := io.Copy(&offsetWriter{w: d.file, offset: start}, body)
+	if err != nil {
+		return fmt.Errorf("unable to download range %d-%d: %v", start, end, err)
+	}
+	if n != end-start+1 {
+		return fmt.Errorf("unable to download range %d-%d: got %d bytes", start, end, n)
+	}
+	return d.complete(idx)
+}
+
+// refreshURL fetches a new download URL unless another worker did so already
+func (d *download) refreshURL(ctx context.Context, expired string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.downloadURL != expired {
+		return d.downloadURL, nil
+	}
+	downloadURL, err := d.drv.downloadURL(ctx, d.item.ID)
+	if err != nil {
+		return "", err
+	}
+	d.downloadURL = downloadURL
+	return downloadURL, nil
+}
+
+// complete records that range idx has been written
+func (d *download) complete(idx int64) error {
+	// the range has to be on disk before it is recorded
+	if err := d.file.Sync(); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.state.Done[idx] = true
+	data, err := json.Marshal(d.state)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(d.statePath, data, 0644); err != nil {
+		return err
+	}
+	if d.progress != nil {
+		var downloaded int64
+		for i, done := range d.state.Done {
+			if !done {
+				continue
+			}
+			downloaded += d.state.RangeSize
+			if int64(i) == int64(len(d.state.Done))-1 && d.item.Size%d.state.RangeSize != 0 {
+				downloaded -= d.state.RangeSize - d.item.Size%d.state.RangeSize
+			}
+		}
+		d.progress(downloaded, d.item.Size)
+	}
+	return nil
+}
+
+// offsetWriter writes to w starting at offset
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+// Write implements io.Writer
+func (ow *offsetWriter) Write(p []byte) (int, error) {
+	n, err := ow.w.WriteAt(p, ow.offset)
+	ow.offset += int64(n)
+	return n, err
+}
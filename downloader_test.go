@@ -0,0 +1,84 @@
+package drive_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+func TestDrive_Download(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100) // 10 ranges of 100 bytes
+	sum := sha1.Sum(content)
+	var mu sync.Mutex
+	fetched := map[string]int{}
+	failing := "bytes=500-599"
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.0/drives/drive-id/root:/file.bin":
+			fmt.Fprintf(w, `{"id":"file-id","size":%d,"lastModifiedDateTime":"2021-01-01T00:00:00Z",`+
+				`"@microsoft.graph.downloadUrl":"http://%s/download","file":{"hashes":{"sha1Hash":"%s"}}}`,
+				len(content), r.Host, hex.EncodeToString(sum[:]))
+		case "/download":
+			rng := r.Header.Get("Range")
+			mu.Lock()
+			fetched[rng]++
+			fail := rng == failing
+			mu.Unlock()
+			if fail {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var start, end int
+			fmt.Sscanf(rng, "bytes=%d-%d", &start, &end)
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(content[start : end+1])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	drv := client.GetDrive("drive-id")
+	local := filepath.Join(t.TempDir(), "file.bin")
+	opts := &drive.DownloadOptions{Workers: 1, RangeSize: 100}
+
+	if _, err := drv.DownloadContext(context.Background(), "/file.bin", local, opts); err == nil {
+		t.Fatal("download did not fail")
+	}
+	if _, err := os.Stat(local + drive.DownloadStateSuffix); err != nil {
+		t.Fatalf("no state file: %v", err)
+	}
+
+	// the second attempt resumes
+	mu.Lock()
+	failing = ""
+	mu.Unlock()
+	if _, err := drv.Download("/file.bin", local, opts); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadFile(local)
+	if !bytes.Equal(data, content) {
+		t.Errorf("unexpected content %q", data)
+	}
+	if fetched["bytes=0-99"] != 1 || fetched["bytes=900-999"] != 1 {
+		t.Errorf("ranges were fetched again: %v", fetched)
+	}
+	if _, err := os.Stat(local + drive.DownloadStateSuffix); !os.IsNotExist(err) {
+		t.Errorf("state file has not been removed: %v", err)
+	}
+
+	// the content is verified
+	content[0] = 'x'
+	_, err := drv.Download("/file.bin", local, &drive.DownloadOptions{Workers: 3, RangeSize: 100})
+	if _, ok := err.(*drive.HashMismatchError); !ok {
+		t.Errorf("expected hash mismatch, got %v", err)
+	}
+}
@@ -1,7 +1,9 @@
 package drive
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"strings"
 )
 
@@ -10,6 +12,9 @@ type Drive struct {
 	Client *Client
 }
 
+// MyDriveID is the ID of the drive of the signed-in user, see GetMyDrive
+const MyDriveID = "me"
+
 func (cli *Client) GetDrive(id string) *Drive {
 	return &Drive{
 		ID:     id,
@@ -17,38 +22,213 @@ func (cli *Client) GetDrive(id string) *Drive {
 	}
 }
 
+// GetMyDrive returns the drive of the signed-in user (/me/drive). This requires a delegated
+// token, e.g. from AuthCodeFlow.
+func (cli *Client) GetMyDrive() *Drive {
+	return cli.GetDrive(MyDriveID)
+}
+
+// base returns the API-call addressing the drive
+func (drv *Drive) base() string {
+	if drv.ID == MyDriveID {
+		return "/me/drive"
+	}
+	return "/drives/" + drv.ID
+}
+
+// page represents a single page of a collection returned by the msgraph API
+type page struct {
+	Items    []*Item `json:"value"`
+	NextLink string  `json:"@odata.nextLink"` // empty on the last page
+}
+
+// ListChildren returns all children of the folder at path. Pages are followed
+// until the listing is complete.
 func (drv *Drive) ListChildren(path string) ([]*Item, error) {
+	return drv.ListChildrenContext(context.Background(), path)
+}
+
+// ListChildrenContext is like ListChildren but performs the API-calls with ctx.
+func (drv *Drive) ListChildrenContext(ctx context.Context, path string) ([]*Item, error) {
+	var items []*Item
+	err := drv.ListChildrenPagesContext(ctx, path, func(page []*Item) bool {
+		items = append(items, page...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// childrenSource returns the API-call listing the children of the folder at path
+func (drv *Drive) childrenSource(path string) string {
 	path = strings.Trim(path, "/")
-	var source string
 	switch path {
 	case "root", "":
-		source = fmt.Sprintf("/drives/%s/items/root/children", drv.ID)
+		return drv.base() + "/items/root/children"
 	default:
-		source = fmt.Sprintf("/drives/%s/items/root:/%s:/children", drv.ID, path)
+		return fmt.Sprintf("%s/items/root:/%s:/children", drv.base(), path)
 	}
-	marsh := &struct {
-		Items []*Item `json:"value"`
-	}{}
-	err := drv.Client.makeGETAPICall(source, nil, marsh)
+}
+
+// ListChildrenPages lists the children of the folder at path page by page and
+// calls fn for every page. Listing stops early when fn returns false.
+func (drv *Drive) ListChildrenPages(path string, fn func(items []*Item) bool) error {
+	return drv.ListChildrenPagesContext(context.Background(), path, fn)
+}
+
+// ListChildrenPagesContext is like ListChildrenPages but performs the API-calls with ctx.
+func (drv *Drive) ListChildrenPagesContext(ctx context.Context, path string, fn func(items []*Item) bool) error {
+	return drv.listPages(ctx, drv.childrenSource(path), fn)
+}
+
+// ListChildrenByID is like ListChildren but addresses the folder by its ID.
+func (drv *Drive) ListChildrenByID(id string) ([]*Item, error) {
+	return drv.ListChildrenByIDContext(context.Background(), id)
+}
+
+// ListChildrenByIDContext is like ListChildrenByID but performs the API-calls with ctx.
+func (drv *Drive) ListChildrenByIDContext(ctx context.Context, id string) ([]*Item, error) {
+	var items []*Item
+	err := drv.ListChildrenPagesByIDContext(ctx, id, func(page []*Item) bool {
+		items = append(items, page...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
-	return marsh.Items, nil
+	return items, nil
+}
+
+// ListChildrenPagesByID is like ListChildrenPages but addresses the folder by its ID.
+func (drv *Drive) ListChildrenPagesByID(id string, fn func(items []*Item) bool) error {
+	return drv.ListChildrenPagesByIDContext(context.Background(), id, fn)
+}
+
+// ListChildrenPagesByIDContext is like ListChildrenPagesByID but performs the API-calls with ctx.
+func (drv *Drive) ListChildrenPagesByIDContext(ctx context.Context, id string, fn func(items []*Item) bool) error {
+	if id == "" {
+		return fmt.Errorf("item ID is empty")
+	}
+	return drv.listPages(ctx, drv.idSource(id)+"/children", fn)
 }
 
+// listPages lists the collection of the API-call source page by page, see ListChildrenPages
+func (drv *Drive) listPages(ctx context.Context, source string, fn func(items []*Item) bool) error {
+	marsh := &page{}
+	err := drv.Client.makeGETAPICall(ctx, source, nil, marsh)
+	for {
+		if err != nil {
+			return err
+		}
+		if !fn(marsh.Items) || marsh.NextLink == "" {
+			return nil
+		}
+		next := marsh.NextLink
+		marsh = &page{}
+		err = drv.Client.makeGETURLCall(ctx, next, marsh)
+	}
+}
+
+// Item returns the item at path.
 func (drv *Drive) Item(path string) (*Item, error) {
-	path = strings.Trim(path, "/")
-	var source string
-	switch path {
-	case "root", "":
-		source = fmt.Sprintf("/drives/%s/items/root", drv.ID)
-	default:
-		source = fmt.Sprintf("/drives/%s/root:/%s", drv.ID, path)
+	return drv.ItemContext(context.Background(), path)
+}
+
+// ItemContext is like Item but performs the API-call with ctx.
+func (drv *Drive) ItemContext(ctx context.Context, path string) (*Item, error) {
+	marsh := &Item{}
+	err := drv.Client.makeGETAPICall(ctx, drv.itemSource(path), nil, marsh)
+	if err != nil {
+		return nil, err
+	}
+	return marsh, nil
+}
+
+// ItemByID returns the item with id. Unlike paths, IDs are kept when an item is renamed or moved.
+func (drv *Drive) ItemByID(id string) (*Item, error) {
+	return drv.ItemByIDContext(context.Background(), id)
+}
+
+// ItemByIDContext is like ItemByID but performs the API-call with ctx.
+func (drv *Drive) ItemByIDContext(ctx context.Context, id string) (*Item, error) {
+	if id == "" {
+		return nil, fmt.Errorf("item ID is empty")
 	}
 	marsh := &Item{}
-	err := drv.Client.makeGETAPICall(source, nil, marsh)
+	err := drv.Client.makeGETAPICall(ctx, drv.idSource(id), nil, marsh)
 	if err != nil {
 		return nil, err
 	}
 	return marsh, nil
 }
+
+// PathByID returns the path of the item with id, e.g. "/folder/file.txt", see Item.Path.
+func (drv *Drive) PathByID(id string) (string, error) {
+	return drv.PathByIDContext(context.Background(), id)
+}
+
+// PathByIDContext is like PathByID but performs the API-call with ctx.
+func (drv *Drive) PathByIDContext(ctx context.Context, id string) (string, error) {
+	item, err := drv.ItemByIDContext(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return item.Path()
+}
+
+// idSource returns the API-call addressing the item with id
+func (drv *Drive) idSource(id string) string {
+	return drv.base() + "/items/" + id
+}
+
+// childSource returns the API-call addressing the item called name in the folder with parentID
+func (drv *Drive) childSource(parentID, name string) string {
+	return fmt.Sprintf("%s:/%s", drv.idSource(parentID), strings.Trim(name, "/"))
+}
+
+// actionSource returns the API-call of action, e.g. "content", of the item of the API-call source.
+// Actions of items addressed by path are separated by a colon.
+func actionSource(source, action string) string {
+	if strings.Contains(source, ":") {
+		return source + ":/" + action
+	}
+	return source + "/" + action
+}
+
+// itemSource returns the API-call addressing the item at path
+func (drv *Drive) itemSource(path string) string {
+	path = strings.Trim(path, "/")
+	switch path {
+	case "root", "":
+		return drv.base() + "/items/root"
+	default:
+		return fmt.Sprintf("%s/root:/%s", drv.base(), path)
+	}
+}
+
+// isRoot reports whether path addresses the root folder
+func isRoot(path string) bool {
+	path = strings.Trim(path, "/")
+	return path == "" || path == "root"
+}
+
+// ConflictBehavior tells msgraph what to do if an item is created where another one exists already
+type ConflictBehavior string
+
+const (
+	ConflictDefault ConflictBehavior = ""        // the default behavior of the API-call
+	ConflictFail    ConflictBehavior = "fail"    // fail with a nameAlreadyExists error
+	ConflictReplace ConflictBehavior = "replace" // replace the existing item
+	ConflictRename  ConflictBehavior = "rename"  // create the item with a new, unique name
+)
+
+// params returns the query parameters requesting the behavior
+func (cb ConflictBehavior) params() url.Values {
+	params := url.Values{}
+	if cb != ConflictDefault {
+		params.Set("@microsoft.graph.conflictBehavior", string(cb))
+	}
+	return params
+}
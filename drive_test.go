@@ -1,8 +1,13 @@
 package drive_test
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strconv"
 	"testing"
+	"time"
 
 	drive "github.com/iochen/msgraph-drive"
 )
@@ -36,3 +41,180 @@ func TestDrive_Item(t *testing.T) {
 	}
 	fmt.Printf("%#v\n", *item)
 }
+
+func TestDrive_Context(t *testing.T) {
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done() // never answer, the caller has to give up
+	}))
+	drv := client.GetDrive("drive-id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := drv.ItemContext(ctx, "/"); err == nil {
+		t.Error("expected error after the deadline")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the deadline has been ignored, the call took %v", elapsed)
+	}
+
+	cancel()
+	if _, err := drv.ListChildrenContext(ctx, "/"); err == nil {
+		t.Error("expected error for a canceled context")
+	}
+	it := drv.ChildrenContext(ctx, "/")
+	if it.Next() || it.Err() == nil {
+		t.Error("expected the iterator to fail for a canceled context")
+	}
+}
+
+// pagedChildren serves the children of the root folder in pages of one item each
+func pagedChildren(t *testing.T, names ...string) *drive.Drive {
+	var srvURL string
+	srv, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1.0/drives/drive-id/items/root/children" {
+			http.NotFound(w, r)
+			return
+		}
+		idx, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		resp := map[string]interface{}{
+			"value": []map[string]string{{"name": names[idx]}},
+		}
+		if idx+1 < len(names) {
+			resp["@odata.nextLink"] = fmt.Sprintf("%s%s?page=%d", srvURL, r.URL.Path, idx+1)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	srvURL = srv.URL
+	return client.GetDrive("drive-id")
+}
+
+func TestDrive_ListChildrenPaging(t *testing.T) {
+	drv := pagedChildren(t, "a", "b", "c")
+	items, err := drv.ListChildren("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[0].Name != "a" || items[2].Name != "c" {
+		t.Errorf("unexpected items %v", items)
+	}
+
+	pages := 0
+	err = drv.ListChildrenPages("", func(items []*drive.Item) bool {
+		pages++
+		return pages < 2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages != 2 {
+		t.Errorf("ListChildrenPages did not stop early, got %d pages", pages)
+	}
+}
+
+func TestDrive_Children(t *testing.T) {
+	drv := pagedChildren(t, "a", "b", "c")
+	var names []string
+	it := drv.Children("root")
+	for it.Next() {
+		names = append(names, it.Item().Name)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 || names[1] != "b" {
+		t.Errorf("unexpected names %v", names)
+	}
+}
+
+func TestDrive_ItemByID(t *testing.T) {
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.0/drives/drive-id/items/root-id":
+			fmt.Fprint(w, `{"id":"root-id","folder":{},"root":{},"parentReference":{"driveId":"drive-id"}}`)
+		case "/v1.0/drives/drive-id/items/file-id":
+			fmt.Fprint(w, `{"id":"file-id","name":"c d.txt","parentReference":{"driveId":"drive-id","id":"folder-id","path":"/drives/drive-id/root:/a/b%20c"}}`)
+		case "/v1.0/drives/drive-id/items/folder-id/children":
+			fmt.Fprint(w, `{"value":[{"id":"file-id","name":"c d.txt"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	drv := client.GetDrive("drive-id")
+
+	item, err := drv.ItemByID("file-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Name != "c d.txt" {
+		t.Errorf("unexpected item %#v", item)
+	}
+	for id, expected := range map[string]string{"file-id": "/a/b c/c d.txt", "root-id": "/"} {
+		p, err := drv.PathByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != expected {
+			t.Errorf("expected path %v of %v, got %v", expected, id, p)
+		}
+	}
+
+	items, err := drv.ListChildrenByID("folder-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != "file-id" {
+		t.Errorf("unexpected children %v", items)
+	}
+	it := drv.ChildrenByID("folder-id")
+	if !it.Next() || it.Item().ID != "file-id" || it.Next() || it.Err() != nil {
+		t.Errorf("unexpected iteration, error %v", it.Err())
+	}
+
+	if _, err := drv.ItemByID(""); err == nil {
+		t.Error("expected error for empty ID")
+	}
+	if it := drv.ChildrenByID(""); it.Next() || it.Err() == nil {
+		t.Error("expected error for empty ID")
+	}
+}
+
+func TestItem_Path(t *testing.T) {
+	for parent, expected := range map[string]string{
+		"/drive/root:":              "/x.txt",
+		"/drive/root:/a":            "/a/x.txt",
+		"/drives/b!id/root:/a/b":    "/a/b/x.txt",
+		"/drives/b!id/root:/100%25": "/100%/x.txt",
+	} {
+		item := &drive.Item{Name: "x.txt"}
+		item.ParentReference.Path, item.ParentReference.ID = parent, "parent-id"
+		p, err := item.Path()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != expected {
+			t.Errorf("expected path %v for parent %v, got %v", expected, parent, p)
+		}
+	}
+
+	item := &drive.Item{Name: "shared.txt"}
+	item.ParentReference.ID = "parent-id"
+	if _, err := item.Path(); err == nil {
+		t.Error("expected error for item without parent path")
+	}
+
+	// search results and shared items may come without parentReference, only the root facet tells the root apart
+	item = &drive.Item{}
+	if err := json.Unmarshal([]byte(`{"id":"found-id","name":"found.txt"}`), item); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := item.Path(); err == nil {
+		t.Errorf("expected error for item without parentReference, got %v", p)
+	}
+	if err := json.Unmarshal([]byte(`{"id":"root-id","root":{},"folder":{}}`), item); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := item.Path(); err != nil || p != "/" {
+		t.Errorf("expected / for the root folder, got %v, %v", p, err)
+	}
+}
@@ -2,7 +2,9 @@ package drive
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 )
 
 type ReqError struct {
@@ -45,3 +47,37 @@ func (re *ReqError) String() string {
 func (re *ReqError) Error() string {
 	return re.String()
 }
+
+// ErrPreconditionFailed is reported if an item has been changed since its ETag has been read,
+// see Drive.Delete. Test for it with errors.Is, the error itself is a *ReqError.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// Is makes errors.Is report a 412 Precondition Failed as ErrPreconditionFailed
+func (re *ReqError) Is(target error) bool {
+	return target == ErrPreconditionFailed && re.StatusCode == http.StatusPreconditionFailed
+}
+
+// oauthError returns the error code of a failed token request, e.g. "invalid_grant". The login endpoint
+// reports errors as {"error": "code", "error_description": "..."} instead of the msgraph error format.
+func oauthError(err error) (code, description string) {
+	re, ok := err.(*ReqError)
+	if !ok {
+		return "", ""
+	}
+	tmp := struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}{}
+	if json.Unmarshal([]byte(re.Raw), &tmp) != nil {
+		return "", ""
+	}
+	return tmp.Error, tmp.Description
+}
+
+// errorCode returns the msgraph error code of err, e.g. "itemNotFound", empty if err is no *ReqError
+func errorCode(err error) string {
+	if re, ok := err.(*ReqError); ok {
+		return re.Err.Code
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Mkdir creates the folder at path and returns it. Its parent folder has to exist already,
+// conflict tells what to do if an item exists at path already.
+func (drv *Drive) Mkdir(path string, conflict ConflictBehavior) (*Item, error) {
+	return drv.MkdirContext(context.Background(), path, conflict)
+}
+
+// MkdirContext is like Mkdir but performs the API-call with ctx.
+func (drv *Drive) MkdirContext(ctx context.Context, folder string, conflict ConflictBehavior) (*Item, error) {
+	folder = strings.Trim(folder, "/")
+	if folder == "" || folder == "root" {
+		return nil, fmt.Errorf("the root folder exists already")
+	}
+	parent, name := path.Split(folder)
+	return drv.mkdir(ctx, drv.childrenSource(parent), name, conflict)
+}
+
+// MkdirByID is like Mkdir but creates the folder called name in the folder with parentID.
+func (drv *Drive) MkdirByID(parentID, name string, conflict ConflictBehavior) (*Item, error) {
+	return drv.MkdirByIDContext(context.Background(), parentID, name, conflict)
+}
+
+// MkdirByIDContext is like MkdirByID but performs the API-call with ctx.
+func (drv *Drive) MkdirByIDContext(ctx context.Context, parentID, name string, conflict ConflictBehavior) (*Item, error) {
+	if parentID == "" {
+		return nil, fmt.Errorf("item ID is empty")
+	}
+	if name = strings.Trim(name, "/"); name == "" {
+		return nil, fmt.Errorf("folder name is empty")
+	}
+	return drv.mkdir(ctx, drv.idSource(parentID)+"/children", name, conflict)
+}
+
+// mkdir creates the folder called name in the children collection of the API-call source
+func (drv *Drive) mkdir(ctx context.Context, source, name string, conflict ConflictBehavior) (*Item, error) {
+	in := map[string]interface{}{
+		"name":   name,
+		"folder": map[string]interface{}{},
+	}
+	if conflict != ConflictDefault {
+		in["@microsoft.graph.conflictBehavior"] = conflict
+	}
+	marsh := &Item{}
+	err := drv.Client.makeAPICall(ctx, "POST", source, nil, in, marsh)
+	if err != nil {
+		return nil, err
+	}
+	return marsh, nil
+}
+
+// MkdirAll creates the folder at path along with all missing parents and returns it. Existing
+// folders are kept, also if they are created concurrently. It fails if an item on the path is
+// not a folder.
+func (drv *Drive) MkdirAll(path string) (*Item, error) {
+	return drv.MkdirAllContext(context.Background(), path)
+}
+
+// MkdirAllContext is like MkdirAll but performs the API-calls with ctx.
+func (drv *Drive) MkdirAllContext(ctx context.Context, folder string) (*Item, error) {
+	folder = strings.Trim(path.Clean("/"+folder), "/")
+	item, err := drv.folder(ctx, folder)
+	if folder == "" || errorCode(err) != "itemNotFound" { // nothing to create
+		return item, err
+	}
+
+	// find the deepest existing parent, then create the folders below it
+	segments := strings.Split(folder, "/")
+	existing := len(segments) - 1
+	for ; existing > 0; existing-- {
+		_, err := drv.folder(ctx, strings.Join(segments[:existing], "/"))
+		if err == nil {
+			break
+		}
+		if errorCode(err) != "itemNotFound" {
+			return nil, err
+		}
+	}
+	for i := existing + 1; i <= len(segments); i++ {
+		current := strings.Join(segments[:i], "/")
+		item, err = drv.MkdirContext(ctx, current, ConflictFail)
+		if errorCode(err) == "nameAlreadyExists" { // created concurrently
+			item, err = drv.folder(ctx, current)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
+}
+
+// folder returns the item at path and fails if it is not a folder
+func (drv *Drive) folder(ctx context.Context, path string) (*Item, error) {
+	item, err := drv.ItemContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if !item.IsFolder() {
+		return nil, fmt.Errorf("%v is not a folder", path)
+	}
+	return item, nil
+}
@@ -0,0 +1,69 @@
+package drive_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+func TestDrive_MkdirAll(t *testing.T) {
+	ft := newFakeTree()
+	_, client := newFakeGraph(t, ft)
+	drv := client.GetDrive("drive-id")
+
+	if _, err := drv.Mkdir("/a", drive.ConflictFail); err != nil {
+		t.Fatal(err)
+	}
+	_, err := drv.Mkdir("/a", drive.ConflictFail)
+	if re, ok := err.(*drive.ReqError); !ok || re.Err.Code != "nameAlreadyExists" {
+		t.Errorf("expected nameAlreadyExists, got %v", err)
+	}
+
+	item, err := drv.MkdirAll("/a/b/c/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != "a/b/c" || ft.items["a/b"] == nil {
+		t.Errorf("unexpected item %#v", item)
+	}
+	if _, err := drv.MkdirAll("a/b/c"); err != nil {
+		t.Errorf("existing folder: %v", err)
+	}
+
+	drv.Put("/a/file", strings.NewReader("x"), 1, drive.ConflictDefault)
+	if _, err := drv.MkdirAll("/a/file/d"); err == nil || !strings.Contains(err.Error(), "not a folder") {
+		t.Errorf("expected not a folder error, got %v", err)
+	}
+}
+
+func TestDrive_MkdirByID(t *testing.T) {
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1.0/drives/drive-id/items/parent-id/children" {
+			http.NotFound(w, r)
+			return
+		}
+		in := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&in)
+		if in["@microsoft.graph.conflictBehavior"] != "rename" || in["folder"] == nil {
+			t.Errorf("unexpected request body %v", in)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":"new-id","name":%q,"folder":{}}`, in["name"])
+	}))
+	drv := client.GetDrive("drive-id")
+
+	item, err := drv.MkdirByID("parent-id", "new", drive.ConflictRename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != "new-id" || item.Name != "new" || !item.IsFolder() {
+		t.Errorf("unexpected item %#v", item)
+	}
+	if _, err := drv.MkdirByID("parent-id", "/", drive.ConflictRename); err == nil {
+		t.Error("expected error for empty name")
+	}
+}
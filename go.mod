@@ -1,8 +1,10 @@
 module github.com/iochen/msgraph-drive
 
-go 1.16
+go 1.19
 
 require (
 	github.com/aws/aws-lambda-go v1.26.0
+	golang.org/x/crypto v0.11.0
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
+	software.sslmate.com/src/go-pkcs12 v0.4.0
 )
@@ -0,0 +1,131 @@
+package drive
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// QuickXorHashSize is the size of a QuickXorHash checksum in bytes
+const QuickXorHashSize int = 20
+
+// quickXorShift is the number of bits every byte is shifted against the previous one
+const quickXorShift = 11
+
+// quickXorHash is the QuickXorHash of OneDrive. Every byte of the content is XORed into a circular
+// 160 bit buffer, shifted by 11 bits against the previous one. The length of the content is XORed
+// into the last 8 bytes of the checksum.
+// See https://docs.microsoft.com/en-us/onedrive/developer/code-snippets/quickxorhash
+type quickXorHash struct {
+	data   [QuickXorHashSize]byte
+	length uint64
+}
+
+// NewQuickXorHash returns a new hash.Hash computing the QuickXorHash checksum, which is reported by
+// every type of drive (see Hashes). The checksum is reported base64 encoded.
+func NewQuickXorHash() hash.Hash {
+	return &quickXorHash{}
+}
+
+// Write implements io.Writer, it never returns an error
+func (h *quickXorHash) Write(p []byte) (int, error) {
+	const width = QuickXorHashSize * 8
+	pos := int((h.length * quickXorShift) % uint64(width)) // the bit position of the next byte
+	for _, b := range p {
+		idx, off := pos/8, uint(pos%8)
+		h.data[idx] ^= b << off
+		if off > 0 {
+			h.data[(idx+1)%QuickXorHashSize] ^= b >> (8 - off)
+		}
+		pos += quickXorShift
+		if pos >= width {
+			pos -= width
+		}
+	}
+	h.length += uint64(len(p))
+	return len(p), nil
+}
+
+// Sum appends the checksum to b
+func (h *quickXorHash) Sum(b []byte) []byte {
+	sum := h.data
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], h.length)
+	for i, l := range length {
+		sum[QuickXorHashSize-len(length)+i] ^= l
+	}
+	return append(b, sum[:]...)
+}
+
+// Reset implements hash.Hash
+func (h *quickXorHash) Reset() {
+	*h = quickXorHash{}
+}
+
+// Size implements hash.Hash
+func (h *quickXorHash) Size() int {
+	return QuickXorHashSize
+}
+
+// BlockSize implements hash.Hash
+func (h *quickXorHash) BlockSize() int {
+	return 64
+}
+
+// HashMismatchError is returned if content does not match a hash reported by msgraph.
+//
+// A failed verification of an upload does not undo it, the file exists with the content received by
+// msgraph. Item tells which file that is, it should be deleted with Drive.DeleteByID passing Item.ETag,
+// hence a file changed in the meantime is kept, or uploaded again with ConflictReplace.
+type HashMismatchError struct {
+	Hash     string // the name of the hash, "quickXorHash", "sha1Hash" or "sha256Hash"
+	Expected string // the hash reported by msgraph
+	Actual   string // the hash of the content
+	Item     *Item  // the item the content has been uploaded to or downloaded from, nil if verified with Hashes.Verify
+}
+
+func (e *HashMismatchError) Error() string {
+	return fmt.Sprintf("%v mismatch: expected %v, got %v", e.Hash, e.Expected, e.Actual)
+}
+
+// Verify reads r and compares the hashes of its content with the reported ones. Hashes which have
+// not been reported are not checked. A mismatch is returned as *HashMismatchError.
+func (hashes Hashes) Verify(r io.Reader) error {
+	type check struct {
+		name     string
+		expected string
+		hash     hash.Hash
+		encode   func([]byte) string
+	}
+	var checks []check
+	var writers []io.Writer
+	add := func(name, expected string, h hash.Hash, encode func([]byte) string) {
+		if expected != "" {
+			checks = append(checks, check{name, expected, h, encode})
+			writers = append(writers, h)
+		}
+	}
+	add("quickXorHash", hashes.QuickXorHash, NewQuickXorHash(), base64.StdEncoding.EncodeToString)
+	add("sha1Hash", strings.ToLower(hashes.SHA1Hash), sha1.New(), hex.EncodeToString) // msgraph reports upper case hex
+	add("sha256Hash", strings.ToLower(hashes.SHA256Hash), sha256.New(), hex.EncodeToString)
+	if len(checks) == 0 {
+		return nil
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return err
+	}
+
+	for _, c := range checks {
+		actual := c.encode(c.hash.Sum(nil))
+		if actual != c.expected {
+			return &HashMismatchError{Hash: c.name, Expected: c.expected, Actual: actual}
+		}
+	}
+	return nil
+}
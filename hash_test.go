@@ -0,0 +1,73 @@
+package drive_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+// testContent returns n bytes of deterministic content
+func testContent(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i*7 + 3)
+	}
+	return b
+}
+
+func TestQuickXorHash(t *testing.T) {
+	// computed with a port of the reference implementation
+	for n, expected := range map[int]string{
+		0:    "AAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+		1:    "AwAAAAAAAAAAAAAAAQAAAAAAAAA=",
+		5:    "A1BABDDwAQAAAAAABQAAAAAAAAA=",
+		160:  "7gi7SoTZMRx5gfdODLshn/kHw6o=",
+		161:  "jQi7SoTZMRx5gfdODbshn/kHw6o=",
+		1000: "dgD8j0n8sM0aPE5CUJ8tqmilX/E=",
+		5000: "4/GMmuF3EXP3HLUJvgacVA3BgPg=",
+	} {
+		content := testContent(n)
+		h := drive.NewQuickXorHash()
+		h.Write(content)
+		if actual := base64.StdEncoding.EncodeToString(h.Sum(nil)); actual != expected {
+			t.Errorf("QuickXorHash of %d bytes = %v, expected %v", n, actual, expected)
+		}
+
+		// the result does not depend on how the content is written
+		h.Reset()
+		for len(content) > 0 {
+			k := 37
+			if k > len(content) {
+				k = len(content)
+			}
+			h.Write(content[:k])
+			content = content[k:]
+		}
+		if actual := base64.StdEncoding.EncodeToString(h.Sum(nil)); actual != expected {
+			t.Errorf("QuickXorHash of %d bytes written in chunks = %v, expected %v", n, actual, expected)
+		}
+	}
+}
+
+func TestHashes_Verify(t *testing.T) {
+	content := testContent(1000)
+	sum := sha1.Sum(content)
+	hashes := drive.Hashes{
+		QuickXorHash: "dgD8j0n8sM0aPE5CUJ8tqmilX/E=",
+		SHA1Hash:     strings.ToUpper(hex.EncodeToString(sum[:])),
+	}
+	if err := hashes.Verify(bytes.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+
+	content[0]++
+	err := hashes.Verify(bytes.NewReader(content))
+	if me, ok := err.(*drive.HashMismatchError); !ok || me.Hash != "quickXorHash" {
+		t.Errorf("expected mismatch of quickXorHash, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// IMDSEndpoint is the token endpoint of the Azure Instance Metadata Service
+const IMDSEndpoint string = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+// Environment variables set by Azure AD workload identity on Kubernetes, see WorkloadIdentity
+const (
+	AzureTenantIDEnv           string = "AZURE_TENANT_ID"
+	AzureClientIDEnv           string = "AZURE_CLIENT_ID"
+	AzureFederatedTokenFileEnv string = "AZURE_FEDERATED_TOKEN_FILE"
+	AzureAuthorityHostEnv      string = "AZURE_AUTHORITY_HOST"
+)
+
+// ManagedIdentity is a TokenSource acquiring tokens of the managed identity of an Azure VM (or any other
+// host serving the Instance Metadata Service), hence without any secret.
+// See https://docs.microsoft.com/en-us/azure/active-directory/managed-identities-azure-resources/how-to-use-vm-token
+type ManagedIdentity struct {
+	ClientID string // the client ID of a user-assigned identity, empty for the system-assigned identity
+
+	Endpoint   string       // the token endpoint, defaults to IMDSEndpoint
+	Resource   string       // the resource to get a token for, defaults to the one of the Client the token is requested for
+	HTTPClient *http.Client // performs the token requests, defaults to the one of the Client the token is requested for
+}
+
+// Token grab's a new token from the metadata service
+func (mi *ManagedIdentity) Token(ctx context.Context) (Token, error) {
+	cli := requester(ctx, mi.HTTPClient)
+	endpoint, resource := mi.Endpoint, mi.Resource
+	if endpoint == "" {
+		endpoint = IMDSEndpoint
+	}
+	if resource == "" {
+		resource = cli.tokenResource()
+	}
+
+	u, err := url.ParseRequestURI(endpoint)
+	if err != nil {
+		return Token{}, fmt.Errorf("unable to parse URI: %v", err)
+	}
+	query := u.Query()
+	query.Set("api-version", "2018-02-01")
+	query.Set("resource", resource)
+	if mi.ClientID != "" {
+		query.Set("client_id", mi.ClientID)
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return Token{}, fmt.Errorf("HTTP Request Error: %v", err)
+	}
+	req.Header.Add("Metadata", "true")
+
+	var newToken Token
+	err = cli.performRequest(req, &newToken)
+	if err != nil {
+		return Token{}, fmt.Errorf("error on getting managed identity Token: %v", err)
+	}
+	return newToken, nil
+}
+
+// WorkloadIdentity is a TokenSource acquiring app-only tokens with a federated token, e.g. the service
+// account token of a Kubernetes pod, as client assertion instead of a secret.
+// See https://docs.microsoft.com/en-us/azure/active-directory/develop/workload-identity-federation
+//
+// Unset fields default to the environment variables set by the Azure AD workload identity webhook.
+type WorkloadIdentity struct {
+	TenantID      string // defaults to $AZURE_TENANT_ID
+	ApplicationID string // defaults to $AZURE_CLIENT_ID
+	TokenFile     string // the file the federated token is read from, defaults to $AZURE_FEDERATED_TOKEN_FILE
+
+	LoginBaseURL string       // the login endpoint, defaults to $AZURE_AUTHORITY_HOST or the one of the Client the token is requested for
+	Resource     string       // the resource to get a token for, defaults to the one of the Client the token is requested for
+	HTTPClient   *http.Client // performs the token requests, defaults to the one of the Client the token is requested for
+}
+
+// Token reads the federated token and exchanges it for a new token
+func (wi *WorkloadIdentity) Token(ctx context.Context) (Token, error) {
+	tokenFile := orEnv(wi.TokenFile, AzureFederatedTokenFileEnv)
+	if tokenFile == "" {
+		return Token{}, fmt.Errorf("no federated token file given and %v is not set", AzureFederatedTokenFileEnv)
+	}
+	cc := &ClientCredentials{
+		TenantID:      orEnv(wi.TenantID, AzureTenantIDEnv),
+		ApplicationID: orEnv(wi.ApplicationID, AzureClientIDEnv),
+		LoginBaseURL:  orEnv(wi.LoginBaseURL, AzureAuthorityHostEnv),
+		Resource:      wi.Resource,
+		HTTPClient:    wi.HTTPClient,
+		Assertion: func(context.Context) (string, error) {
+			// the file is rotated, hence it is read for every token
+			data, err := ioutil.ReadFile(tokenFile)
+			if err != nil {
+				return "", fmt.Errorf("unable to read federated token: %v", err)
+			}
+			return strings.TrimSpace(string(data)), nil
+		},
+	}
+	return cc.Token(ctx)
+}
+
+// orEnv returns value, or the environment variable key if value is empty
+func orEnv(value, key string) string {
+	if value != "" {
+		return value
+	}
+	return os.Getenv(key)
+}
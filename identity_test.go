@@ -0,0 +1,73 @@
+package drive_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+func TestManagedIdentity(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Header.Get("Metadata") != "true" || query.Get("resource") != drive.BaseURL || query.Get("client_id") != "identity" {
+			t.Errorf("unexpected request %v %v", r.URL, r.Header)
+		}
+		// IMDS reports numbers as strings like the v1 endpoint
+		json.NewEncoder(w).Encode(map[string]string{
+			"token_type":   "Bearer",
+			"expires_on":   strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10),
+			"not_before":   strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10),
+			"resource":     query.Get("resource"),
+			"access_token": "managed-token",
+		})
+	}))
+	defer srv.Close()
+
+	src := &drive.ManagedIdentity{ClientID: "identity", Endpoint: srv.URL + "/metadata/identity/oauth2/token"}
+	token, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "managed-token" {
+		t.Errorf("unexpected token %+v", token)
+	}
+}
+
+func TestWorkloadIdentity(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("federated-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tenant/oauth2/v2.0/token" || r.FormValue("client_id") != "application" ||
+			r.FormValue("client_assertion_type") != drive.ClientAssertionType || r.FormValue("client_assertion") != "federated-token" {
+			t.Errorf("unexpected request %v %v", r.URL, r.Form)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token_type":   "Bearer",
+			"expires_in":   3599,
+			"access_token": "workload-token",
+		})
+	}))
+	defer srv.Close()
+
+	os.Setenv(drive.AzureFederatedTokenFileEnv, tokenFile)
+	defer os.Unsetenv(drive.AzureFederatedTokenFileEnv)
+	src := &drive.WorkloadIdentity{TenantID: "tenant", ApplicationID: "application", LoginBaseURL: srv.URL}
+	token, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "workload-token" {
+		t.Errorf("unexpected token %+v", token)
+	}
+}
@@ -1,6 +1,12 @@
 package drive
 
-import "time"
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
 
 type User struct {
 	Email       string `json:"email"`
@@ -15,6 +21,14 @@ type Reference struct {
 	Path      string `json:"path"`
 }
 
+// Hashes are the hashes of the content of a file reported by msgraph. Which of them are
+// available depends on the type of the drive.
+type Hashes struct {
+	SHA1Hash     string `json:"sha1Hash,omitempty"`     // hex encoded, OneDrive personal only
+	SHA256Hash   string `json:"sha256Hash,omitempty"`   // hex encoded, OneDrive personal only
+	QuickXorHash string `json:"quickXorHash,omitempty"` // base64 encoded
+}
+
 type Item struct {
 	CreatedAt time.Time `json:"createdDateTime"`
 	ID        string    `json:"id"`
@@ -22,6 +36,8 @@ type Item struct {
 	Name      string    `json:"name"`
 	WebURL    string    `json:"webUrl"`
 	Size      int64     `json:"size"`
+	ETag      string    `json:"eTag"` // changes with every change of the item, see Drive.Delete
+	CTag      string    `json:"cTag"` // changes with every change of the content
 
 	CreatedBy struct {
 		User `json:"user"`
@@ -40,11 +56,13 @@ type Item struct {
 	Folder *struct {
 		ChildCount int `json:"childCount"`
 	} `json:"folder,omitempty"`
+	Root *struct{} `json:"root,omitempty"` // only reported for the root folder of a drive
 
 	DownloadURL string `json:"@microsoft.graph.downloadUrl,omitempty"`
 
 	File *struct {
 		MimeType string `json:"mimeType"`
+		Hashes   Hashes `json:"hashes"`
 	} `json:"file,omitempty"`
 
 	Image *struct {
@@ -56,3 +74,26 @@ type Item struct {
 func (item *Item) IsFolder() bool {
 	return item.Folder != nil
 }
+
+// Path returns the path of the item within its drive, e.g. "/folder/file.txt", or "/" for the root
+// folder. It is derived from ParentReference.Path, which is not reported for all items, e.g. not
+// for items listed by a search or shared from another drive.
+func (item *Item) Path() (string, error) {
+	if item.Root != nil {
+		return "/", nil
+	}
+	parent := item.ParentReference.Path
+	if parent == "" {
+		return "", fmt.Errorf("path of %v has not been reported", item.Name)
+	}
+	// the parent path is reported relative to its drive, e.g. "/drives/{drive-id}/root:/folder"
+	idx := strings.Index(parent, "root:")
+	if idx < 0 {
+		return "", fmt.Errorf("malformed parent path %q", parent)
+	}
+	parent = parent[idx+len("root:"):]
+	if unescaped, err := url.PathUnescape(parent); err == nil {
+		parent = unescaped
+	}
+	return path.Join("/", parent, item.Name), nil
+}
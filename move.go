@@ -0,0 +1,93 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+)
+
+// Move moves the item at srcPath into the folder at dstParentPath and returns the moved item.
+// dstParentPath addresses the root folder if it is empty or "root", like in Item. The item is
+// renamed to newName unless it is empty. conflict tells what to do if the destination exists already.
+func (drv *Drive) Move(srcPath, dstParentPath, newName string, conflict ConflictBehavior) (*Item, error) {
+	return drv.MoveContext(context.Background(), srcPath, dstParentPath, newName, conflict)
+}
+
+// MoveContext is like Move but performs the API-calls with ctx.
+func (drv *Drive) MoveContext(ctx context.Context, srcPath, dstParentPath, newName string, conflict ConflictBehavior) (*Item, error) {
+	if isRoot(srcPath) {
+		return nil, fmt.Errorf("the root folder can not be moved")
+	}
+	parent, err := drv.folder(ctx, dstParentPath)
+	if err != nil {
+		return nil, err
+	}
+	in := map[string]interface{}{
+		"parentReference": map[string]string{"id": parent.ID},
+	}
+	if newName != "" {
+		in["name"] = newName
+	}
+	return drv.update(ctx, drv.itemSource(srcPath), in, conflict)
+}
+
+// MoveByID is like Move but addresses the item and the destination folder by their IDs.
+func (drv *Drive) MoveByID(id, dstParentID, newName string, conflict ConflictBehavior) (*Item, error) {
+	return drv.MoveByIDContext(context.Background(), id, dstParentID, newName, conflict)
+}
+
+// MoveByIDContext is like MoveByID but performs the API-call with ctx.
+func (drv *Drive) MoveByIDContext(ctx context.Context, id, dstParentID, newName string, conflict ConflictBehavior) (*Item, error) {
+	if id == "" || dstParentID == "" {
+		return nil, fmt.Errorf("item ID is empty")
+	}
+	in := map[string]interface{}{
+		"parentReference": map[string]string{"id": dstParentID},
+	}
+	if newName != "" {
+		in["name"] = newName
+	}
+	return drv.update(ctx, drv.idSource(id), in, conflict)
+}
+
+// Rename renames the item at path to newName and returns the renamed item. conflict tells what to do
+// if an item called newName exists already.
+func (drv *Drive) Rename(path, newName string, conflict ConflictBehavior) (*Item, error) {
+	return drv.RenameContext(context.Background(), path, newName, conflict)
+}
+
+// RenameContext is like Rename but performs the API-call with ctx.
+func (drv *Drive) RenameContext(ctx context.Context, path, newName string, conflict ConflictBehavior) (*Item, error) {
+	if isRoot(path) {
+		return nil, fmt.Errorf("the root folder can not be renamed")
+	}
+	if newName == "" {
+		return nil, fmt.Errorf("new name is empty")
+	}
+	return drv.update(ctx, drv.itemSource(path), map[string]interface{}{"name": newName}, conflict)
+}
+
+// RenameByID is like Rename but addresses the item by its ID.
+func (drv *Drive) RenameByID(id, newName string, conflict ConflictBehavior) (*Item, error) {
+	return drv.RenameByIDContext(context.Background(), id, newName, conflict)
+}
+
+// RenameByIDContext is like RenameByID but performs the API-call with ctx.
+func (drv *Drive) RenameByIDContext(ctx context.Context, id, newName string, conflict ConflictBehavior) (*Item, error) {
+	if id == "" {
+		return nil, fmt.Errorf("item ID is empty")
+	}
+	if newName == "" {
+		return nil, fmt.Errorf("new name is empty")
+	}
+	return drv.update(ctx, drv.idSource(id), map[string]interface{}{"name": newName}, conflict)
+}
+
+// update patches the item of the API-call source with in and returns the updated item
+func (drv *Drive) update(ctx context.Context, source string, in map[string]interface{}, conflict ConflictBehavior) (*Item, error) {
+	marsh := &Item{}
+	err := drv.Client.makeAPICall(ctx, "PATCH", source, conflict.params(), in, marsh)
+	if err != nil {
+		return nil, err
+	}
+	return marsh, nil
+}
@@ -0,0 +1,90 @@
+package drive_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+func TestDrive_Move(t *testing.T) {
+	var patches []map[string]interface{}
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1.0/drives/drive-id/items/root":
+			fmt.Fprint(w, `{"id":"root-id","folder":{}}`)
+		case r.Method == "GET" && r.URL.Path == "/v1.0/drives/drive-id/root:/dest":
+			fmt.Fprint(w, `{"id":"dest-id","folder":{}}`)
+		case r.Method == "PATCH" && r.URL.Path == "/v1.0/drives/drive-id/root:/src/a.txt":
+			in := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&in)
+			in["conflict"] = r.URL.Query().Get("@microsoft.graph.conflictBehavior")
+			patches = append(patches, in)
+			fmt.Fprint(w, `{"id":"a-id","name":"b.txt"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	drv := client.GetDrive("drive-id")
+
+	item, err := drv.Move("/src/a.txt", "/dest", "b.txt", drive.ConflictReplace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Name != "b.txt" {
+		t.Errorf("unexpected item %#v", item)
+	}
+	if _, err := drv.Move("/src/a.txt", "root", "", drive.ConflictDefault); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := drv.Rename("/src/a.txt", "c.txt", drive.ConflictFail); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"conflict":"replace","name":"b.txt","parentReference":{"id":"dest-id"}}`,
+		`{"conflict":"","parentReference":{"id":"root-id"}}`,
+		`{"conflict":"fail","name":"c.txt"}`,
+	}
+	for i, patch := range patches {
+		if data, _ := json.Marshal(patch); i >= len(expected) || string(data) != expected[i] {
+			t.Errorf("unexpected patch %s", data)
+		}
+	}
+	if len(patches) != len(expected) {
+		t.Errorf("expected %d patches, got %d", len(expected), len(patches))
+	}
+}
+
+func TestDrive_MoveByID(t *testing.T) {
+	var patches []string
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/v1.0/drives/drive-id/items/a-id" {
+			http.NotFound(w, r)
+			return
+		}
+		in := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&in)
+		data, _ := json.Marshal(in)
+		patches = append(patches, string(data))
+		fmt.Fprint(w, `{"id":"a-id"}`)
+	}))
+	drv := client.GetDrive("drive-id")
+
+	if _, err := drv.MoveByID("a-id", "dest-id", "", drive.ConflictDefault); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := drv.RenameByID("a-id", "b.txt", drive.ConflictDefault); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := drv.MoveByID("", "dest-id", "", drive.ConflictDefault); err == nil {
+		t.Error("expected error for empty ID")
+	}
+
+	expected := []string{`{"parentReference":{"id":"dest-id"}}`, `{"name":"b.txt"}`}
+	if len(patches) != len(expected) || patches[0] != expected[0] || patches[1] != expected[1] {
+		t.Errorf("unexpected patches %v", patches)
+	}
+}
@@ -0,0 +1,123 @@
+package drive
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configures a Client, see NewGraphClient.
+type Option func(cli *Client)
+
+// defaultHTTPClient is used by every Client which has not been given its own http.Client
+var defaultHTTPClient = &http.Client{Timeout: DefaultTimeout}
+
+// WithHTTPClient makes the Client perform all requests with httpClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(cli *Client) {
+		cli.httpClient = httpClient
+	}
+}
+
+// WithTransport makes the Client send all requests through rt, e.g. to use a proxy.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(cli *Client) {
+		c := cli.copyHTTPClient()
+		c.Transport = rt
+		cli.httpClient = c
+	}
+}
+
+// WithTimeout sets the time limit of a single request including reading the response body.
+// A timeout of zero means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cli *Client) {
+		c := cli.copyHTTPClient()
+		c.Timeout = timeout
+		cli.httpClient = c
+	}
+}
+
+// WithLoginBaseURL overrides LoginBaseURL, the URL used to acquire tokens.
+func WithLoginBaseURL(loginBaseURL string) Option {
+	return func(cli *Client) {
+		cli.loginBaseURL = loginBaseURL
+	}
+}
+
+// WithBaseURL overrides BaseURL, the URL all API-calls are sent to.
+func WithBaseURL(baseURL string) Option {
+	return func(cli *Client) {
+		cli.baseURL = baseURL
+	}
+}
+
+// WithAPIVersion overrides APIVersion, e.g. to use "beta".
+func WithAPIVersion(apiVersion string) Option {
+	return func(cli *Client) {
+		cli.apiVersion = apiVersion
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(cli *Client) {
+		cli.userAgent = userAgent
+	}
+}
+
+// WithV1Endpoint makes a Client created by NewGraphClient acquire its tokens from the
+// legacy Azure AD v1 endpoint only. Without it the Microsoft identity platform v2.0 endpoint
+// is used and the v1 endpoint is only a fallback, see ClientCredentials.
+func WithV1Endpoint() Option {
+	return func(cli *Client) {
+		cli.v1Endpoint = true
+	}
+}
+
+// copyHTTPClient returns a copy of the http.Client currently used, hence options
+// never modify an http.Client passed by the caller
+func (cli *Client) copyHTTPClient() *http.Client {
+	c := *cli.http()
+	return &c
+}
+
+// http returns the http.Client to perform requests with
+func (cli *Client) http() *http.Client {
+	if cli.httpClient != nil {
+		return cli.httpClient
+	}
+	return defaultHTTPClient
+}
+
+// loginURL returns the URL used to acquire tokens
+func (cli *Client) loginURL() string {
+	if cli.loginBaseURL != "" {
+		return cli.loginBaseURL
+	}
+	return LoginBaseURL
+}
+
+// graphURL returns the URL all API-calls are sent to
+func (cli *Client) graphURL() string {
+	if cli.baseURL != "" {
+		return cli.baseURL
+	}
+	return BaseURL
+}
+
+// tokenResource returns the resource tokens are acquired for. This does not change with
+// WithBaseURL, only with WithCloud.
+func (cli *Client) tokenResource() string {
+	if cli.resource != "" {
+		return cli.resource
+	}
+	return BaseURL
+}
+
+// version returns the msgraph API version used for API-calls
+func (cli *Client) version() string {
+	if cli.apiVersion != "" {
+		return cli.apiVersion
+	}
+	return APIVersion
+}
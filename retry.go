@@ -0,0 +1,170 @@
+package drive
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried.
+//
+// Throttled requests (429 Too Many Requests and 503 Service Unavailable) are retried after the
+// delay given by their Retry-After header. Transient network errors and other 5xx responses
+// are only retried for idempotent methods. Without Retry-After the delay grows exponentially
+// from MinBackoff up to MaxBackoff and is jittered.
+type RetryPolicy struct {
+	MaxAttempts int           // maximum number of attempts including the first one, values below 2 disable retries
+	MinBackoff  time.Duration // delay before the first retry
+	MaxBackoff  time.Duration // maximum delay between two attempts
+	Budget      time.Duration // maximum total delay of all retries of a request, zero means no limit
+}
+
+// DefaultRetryPolicy is used by every Client which has not been given a policy with WithRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  30 * time.Second,
+	Budget:      2 * time.Minute,
+}
+
+// NoRetry disables retries
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// WithRetryPolicy sets the policy failed requests are retried with.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(cli *Client) {
+		cli.retry = &policy
+	}
+}
+
+// retryPolicy returns the policy failed requests are retried with
+func (cli *Client) retryPolicy() RetryPolicy {
+	if cli.retry != nil {
+		return *cli.retry
+	}
+	return DefaultRetryPolicy
+}
+
+// backoff returns the jittered delay before the given retry (starting at 1)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// isIdempotent reports whether a request with the given method can safely be sent again
+func isIdempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header, which is either a number of seconds or a date
+func retryAfter(header http.Header) (time.Duration, bool) {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// rewind prepares req to be sent again, it returns false if the body can not be replayed
+func rewind(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	req.Body = body
+	return true
+}
+
+// do sends req and retries it according to the retry policy. A response with a status code
+// other than 2xx is returned as *ReqError, otherwise the caller has to close the response body.
+func (cli *Client) do(req *http.Request) (*http.Response, error) {
+	if cli.userAgent != "" {
+		req.Header.Set("User-Agent", cli.userAgent)
+	}
+	policy := cli.retryPolicy()
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		var (
+			reqErr error
+			retry  bool
+			wait   time.Duration
+			hasRA  bool
+		)
+		resp, err := cli.http().Do(req)
+		switch {
+		case err != nil:
+			if req.Context().Err() != nil { // canceled by the caller, do not retry
+				return nil, fmt.Errorf("HTTP response error: %v of http.Request: %v", err, req.URL)
+			}
+			reqErr = fmt.Errorf("HTTP response error: %v of http.Request: %v", err, req.URL)
+			retry = isIdempotent(req.Method)
+		case resp.StatusCode < 200 || resp.StatusCode > 299:
+			// Hint: this will mostly be the case if the tenant ID can not be found, the Application ID can not be found or the clientSecret is incorrect.
+			// The cause will be described in the body, hence we have to return the body too for proper error-analysis
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			reqErr = NewErr(resp.StatusCode, body)
+			switch {
+			case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+				retry = true
+				wait, hasRA = retryAfter(resp.Header)
+			case resp.StatusCode >= 500:
+				retry = isIdempotent(req.Method)
+			}
+		default:
+			return resp, nil
+		}
+
+		// a body which can not be sent again makes the failure final
+		if !retry || attempt >= policy.MaxAttempts || !rewind(req) {
+			return nil, reqErr
+		}
+		if !hasRA {
+			wait = policy.backoff(attempt)
+		}
+		if policy.Budget > 0 && waited+wait > policy.Budget {
+			return nil, reqErr
+		}
+		waited += wait
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, reqErr
+		case <-timer.C:
+		}
+	}
+}
@@ -3,15 +3,20 @@ package drive
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Token struct {
 	TokenType   string    // should always be "Bearer" for msgraph API-calls
-	NotBefore   time.Time // time when the access token starts to be valid
+	NotBefore   time.Time // time when the access token starts to be valid, zero if not reported (v2.0 endpoint)
 	ExpiresOn   time.Time // time when the access token expires
-	Resource    string    // will most likely always be https://graph.microsoft.com, hence the BaseURL
+	Resource    string    // will most likely always be https://graph.microsoft.com, hence the BaseURL. Empty for the v2.0 endpoint
 	AccessToken string    // the access-token itself
+
+	RefreshToken string   // used to acquire a new token without user interaction, only for delegated tokens
+	Scopes       []string // the scopes granted to the access-token, only reported by the v2.0 endpoint
 }
 
 func (t Token) String() string {
@@ -63,17 +68,21 @@ func (t Token) WantsToBeRefreshed() bool {
 }
 
 // UnmarshalJSON implements the json unmarshal to be used by the json-library.
+// Responses of both the v1 and the v2.0 endpoint are supported. The latter does not
+// report expires_on and not_before, ExpiresOn is computed from expires_in instead.
 //
 // Hint: the UnmarshalJSON also checks immediately if the token is valid, hence
 // the current time.Now() is after NotBefore and before ExpiresOn
 func (t *Token) UnmarshalJSON(data []byte) error {
 	tmp := struct {
-		TokenType   string `json:"token_type"`        // should normally be "Bearer"
-		ExpiresOn   int64  `json:"expires_on,string"` // = UNIX timestamp, parse to int64 immediately
-		NotBefore   int64  `json:"not_before,string"` // = UNIX timestamp, parse to int64 immediately
-		Resource    string `json:"resource"`          // will typically be https://graph.microsoft.com or wherever it came from
-		AccessToken string `json:"access_token"`      // the actual access token - veeery long string
-		//ExpiresIn   string `json:"expires_in"` // not used
+		TokenType   string  `json:"token_type"`    // should normally be "Bearer"
+		ExpiresOn   flexInt `json:"expires_on"`    // = UNIX timestamp, only v1
+		NotBefore   flexInt `json:"not_before"`    // = UNIX timestamp, only v1
+		ExpiresIn   flexInt `json:"expires_in"`    // = seconds the token is valid from now on
+		Resource    string  `json:"resource"`      // will typically be https://graph.microsoft.com or wherever it came from, only v1
+		AccessToken string  `json:"access_token"`  // the actual access token - veeery long string
+		RefreshTok  string  `json:"refresh_token"` // only for delegated flows requesting offline_access
+		Scope       string  `json:"scope"`         // space separated, only v2
 	}{}
 
 	// unmarshal to tmp-struct, return if error
@@ -82,10 +91,22 @@ func (t *Token) UnmarshalJSON(data []byte) error {
 	}
 
 	t.TokenType = tmp.TokenType
-	t.ExpiresOn = time.Unix(tmp.ExpiresOn, 0)
-	t.NotBefore = time.Unix(tmp.NotBefore, 0)
+	switch {
+	case tmp.ExpiresOn != 0:
+		t.ExpiresOn = time.Unix(int64(tmp.ExpiresOn), 0)
+	case tmp.ExpiresIn != 0:
+		t.ExpiresOn = time.Now().Add(time.Duration(tmp.ExpiresIn) * time.Second)
+	default:
+		return fmt.Errorf("Access-Token has neither expires_on nor expires_in")
+	}
+	t.NotBefore = time.Time{}
+	if tmp.NotBefore != 0 {
+		t.NotBefore = time.Unix(int64(tmp.NotBefore), 0)
+	}
 	t.Resource = tmp.Resource
 	t.AccessToken = tmp.AccessToken
+	t.RefreshToken = tmp.RefreshTok
+	t.Scopes = strings.Fields(tmp.Scope)
 
 	if t.HasExpired() {
 		return fmt.Errorf("Access-Token ExpiresOn %v is before current system-time %v", t.ExpiresOn, time.Now())
@@ -96,3 +117,22 @@ func (t *Token) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// flexInt is an integer encoded either as JSON number or as string. The v1 endpoint
+// returns numbers as strings, the v2.0 endpoint as numbers.
+type flexInt int64
+
+// UnmarshalJSON implements the json unmarshal to be used by the json-library.
+func (i *flexInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = flexInt(n)
+	return nil
+}
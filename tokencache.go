@@ -0,0 +1,238 @@
+package drive
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// TokenCacheKeyEnv is the environment variable the key of a FileTokenCache is read from if none is given
+const TokenCacheKeyEnv string = "MSGRAPH_TOKEN_CACHE_KEY"
+
+// ErrNoCachedToken is returned by TokenCache.Load if the cache is empty
+var ErrNoCachedToken = errors.New("no cached token")
+
+// TokenCache stores a token across restarts, hence a Client does not have to acquire a new token
+// on every start and delegated refresh tokens are not lost. A cache must only hold the tokens of
+// a single identity, see WithTokenCache.
+type TokenCache interface {
+	Load(ctx context.Context) (Token, error) // returns ErrNoCachedToken if the cache is empty
+	Store(ctx context.Context, token Token) error
+}
+
+// WithTokenCache makes the Client read its token from cache before acquiring a new one. Every
+// newly acquired token is written to cache.
+func WithTokenCache(cache TokenCache) Option {
+	return func(cli *Client) {
+		cli.cache = cache
+	}
+}
+
+// CachedTokenSource returns a TokenSource which returns the token of cache as long as it does not
+// want to be refreshed, afterwards the token of src is returned and stored in cache. Failing to store
+// a token does not fail the token request, the token is simply acquired again after a restart.
+func CachedTokenSource(cache TokenCache, src TokenSource) TokenSource {
+	return &cachedSource{cache: cache, src: src}
+}
+
+// cachedSource is the TokenSource returned by CachedTokenSource
+type cachedSource struct {
+	cache TokenCache
+	src   TokenSource
+
+	mu     sync.Mutex
+	loaded bool // the cache is only read once, afterwards src is in charge
+}
+
+// Token returns the cached token or the one of src
+func (cs *cachedSource) Token(ctx context.Context) (Token, error) {
+	cs.mu.Lock()
+	loaded := cs.loaded
+	cs.loaded = true
+	cs.mu.Unlock()
+	if !loaded {
+		if token, err := cs.cache.Load(ctx); err == nil && !token.WantsToBeRefreshed() {
+			return token, nil
+		}
+	}
+
+	token, err := cs.src.Token(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+	cs.cache.Store(ctx, token)
+	return token, nil
+}
+
+// MemoryTokenCache is a TokenCache holding the token in memory, e.g. to share it between Clients.
+// The zero value is an empty cache.
+type MemoryTokenCache struct {
+	mu    sync.Mutex
+	token *Token
+}
+
+// Load returns the cached token
+func (mc *MemoryTokenCache) Load(ctx context.Context) (Token, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.token == nil {
+		return Token{}, ErrNoCachedToken
+	}
+	return *mc.token, nil
+}
+
+// Store replaces the cached token
+func (mc *MemoryTokenCache) Store(ctx context.Context, token Token) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.token = &token
+	return nil
+}
+
+// FileTokenCache is a TokenCache storing the token in a file. The file is encrypted with AES-256-GCM
+// using a key derived from a passphrase with scrypt and replaced atomically on every Store.
+type FileTokenCache struct {
+	path       string
+	passphrase []byte
+
+	mu sync.Mutex // serializes writes
+}
+
+// NewFileTokenCache returns a FileTokenCache storing the token at path encrypted with passphrase.
+// If passphrase is empty it is read from the environment variable TokenCacheKeyEnv.
+func NewFileTokenCache(path, passphrase string) (*FileTokenCache, error) {
+	if passphrase == "" {
+		passphrase = os.Getenv(TokenCacheKeyEnv)
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("no token cache key given and %v is not set", TokenCacheKeyEnv)
+	}
+	return &FileTokenCache{path: path, passphrase: []byte(passphrase)}, nil
+}
+
+// tokenFile is the content of the file of a FileTokenCache
+type tokenFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`  // scrypt salt
+	Nonce   []byte `json:"nonce"` // AES-GCM nonce
+	Data    []byte `json:"data"`  // the encrypted cachedToken
+}
+
+// cachedToken is the representation of a Token in a cache. Token itself unmarshals the
+// responses of the login endpoint.
+type cachedToken struct {
+	TokenType    string    `json:"tokenType"`
+	NotBefore    time.Time `json:"notBefore"`
+	ExpiresOn    time.Time `json:"expiresOn"`
+	Resource     string    `json:"resource,omitempty"`
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	Scopes       []string  `json:"scopes,omitempty"`
+}
+
+// Load decrypts the cached token. A missing file is reported as ErrNoCachedToken.
+func (fc *FileTokenCache) Load(ctx context.Context) (Token, error) {
+	data, err := ioutil.ReadFile(fc.path)
+	if os.IsNotExist(err) {
+		return Token{}, ErrNoCachedToken
+	}
+	if err != nil {
+		return Token{}, err
+	}
+	var tf tokenFile
+	if err := json.Unmarshal(data, &tf); err != nil || tf.Version != 1 {
+		return Token{}, fmt.Errorf("token cache %v is malformed", fc.path)
+	}
+	aead, err := fc.cipher(tf.Salt)
+	if err != nil {
+		return Token{}, err
+	}
+	plain, err := aead.Open(nil, tf.Nonce, tf.Data, nil)
+	if err != nil {
+		return Token{}, fmt.Errorf("unable to decrypt token cache %v, wrong key?", fc.path)
+	}
+	var ct cachedToken
+	if err := json.Unmarshal(plain, &ct); err != nil {
+		return Token{}, fmt.Errorf("token cache %v is malformed: %v", fc.path, err)
+	}
+	return Token(ct), nil
+}
+
+// Store encrypts token and replaces the file atomically
+func (fc *FileTokenCache) Store(ctx context.Context, token Token) error {
+	plain, err := json.Marshal(cachedToken(token))
+	if err != nil {
+		return err
+	}
+	tf := tokenFile{Version: 1, Salt: make([]byte, 16)}
+	if _, err := rand.Read(tf.Salt); err != nil {
+		return err
+	}
+	aead, err := fc.cipher(tf.Salt)
+	if err != nil {
+		return err
+	}
+	tf.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(tf.Nonce); err != nil {
+		return err
+	}
+	tf.Data = aead.Seal(nil, tf.Nonce, plain, nil)
+	data, err := json.Marshal(tf)
+	if err != nil {
+		return err
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return writeFileAtomic(fc.path, data, 0600)
+}
+
+// cipher returns the AES-GCM cipher of the key derived from the passphrase and salt
+func (fc *FileTokenCache) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(fc.passphrase, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it to path,
+// hence readers either see the previous or the new content
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails once the file has been renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
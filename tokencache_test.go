@@ -0,0 +1,77 @@
+package drive_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+func TestFileTokenCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	cache, err := drive.NewFileTokenCache(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Load(context.Background()); err != drive.ErrNoCachedToken {
+		t.Errorf("expected ErrNoCachedToken, got %v", err)
+	}
+
+	token := drive.Token{
+		TokenType:    "Bearer",
+		ExpiresOn:    time.Now().Add(time.Hour).Round(time.Second),
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		Scopes:       []string{"offline_access"},
+	}
+	if err := cache.Store(context.Background(), token); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("unexpected file info %v, error %v", info, err)
+	}
+	loaded, err := cache.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.AccessToken != "access" || loaded.RefreshToken != "refresh" || !loaded.ExpiresOn.Equal(token.ExpiresOn) {
+		t.Errorf("unexpected token %+v", loaded)
+	}
+
+	wrong, _ := drive.NewFileTokenCache(path, "wrong")
+	if _, err := wrong.Load(context.Background()); err == nil {
+		t.Error("token could be decrypted with the wrong key")
+	}
+}
+
+func TestClient_TokenCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected token request %v", r.URL)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	cache := &drive.MemoryTokenCache{}
+	cache.Store(context.Background(), drive.Token{
+		TokenType:   "Bearer",
+		ExpiresOn:   time.Now().Add(time.Hour),
+		AccessToken: "cached",
+	})
+	_, err := drive.NewGraphClient("tenant", "application", "secret", drive.WithLoginBaseURL(srv.URL), drive.WithTokenCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a new token is written to the cache
+	cache = &drive.MemoryTokenCache{}
+	newFakeGraph(t, http.NotFoundHandler(), drive.WithTokenCache(cache))
+	token, err := cache.Load(context.Background())
+	if err != nil || token.AccessToken != "fake-token" {
+		t.Errorf("unexpected cached token %+v, error %v", token, err)
+	}
+}
@@ -0,0 +1,129 @@
+package drive
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// TokenSource supplies the tokens a Client authenticates its API-calls with.
+//
+// Token is called every time a Client needs a token, wrap a TokenSource with
+// RefreshingTokenSource to reuse a token until it wants to be refreshed.
+// NewClient does that already.
+type TokenSource interface {
+	Token(ctx context.Context) (Token, error)
+}
+
+// TokenSourceFunc is an adapter to use an ordinary function as TokenSource
+type TokenSourceFunc func(ctx context.Context) (Token, error)
+
+// Token calls f(ctx)
+func (f TokenSourceFunc) Token(ctx context.Context) (Token, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource returns a TokenSource which always returns token. The token is never refreshed.
+func StaticTokenSource(token Token) TokenSource {
+	return TokenSourceFunc(func(context.Context) (Token, error) {
+		return token, nil
+	})
+}
+
+// refreshingSource caches the token of src until it wants to be refreshed
+type refreshingSource struct {
+	src TokenSource
+
+	mu         sync.Mutex   // protects token and refreshing
+	token      Token        // the current token
+	refreshing *refreshCall // the refresh in flight, if any
+}
+
+// refreshCall is a token refresh in flight, done is closed once it finished
+type refreshCall struct {
+	done  chan struct{}
+	token Token
+	err   error
+}
+
+// RefreshingTokenSource returns a TokenSource which reuses the token of src until it
+// wants to be refreshed (see Token.WantsToBeRefreshed). Only one refresh is performed
+// at a time, concurrent callers wait for the refresh in flight and share its result.
+func RefreshingTokenSource(src TokenSource) TokenSource {
+	if rs, ok := src.(*refreshingSource); ok {
+		return rs
+	}
+	return &refreshingSource{src: src}
+}
+
+// Token returns the cached token or refreshes it
+func (rs *refreshingSource) Token(ctx context.Context) (Token, error) {
+	rs.mu.Lock()
+	if !rs.token.WantsToBeRefreshed() {
+		token := rs.token
+		rs.mu.Unlock()
+		return token, nil
+	}
+	if call := rs.refreshing; call != nil {
+		rs.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.token, call.err
+		case <-ctx.Done():
+			return Token{}, ctx.Err()
+		}
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	rs.refreshing = call
+	rs.mu.Unlock()
+
+	call.token, call.err = rs.src.Token(ctx)
+
+	rs.mu.Lock()
+	if call.err == nil {
+		rs.token = call.token
+	}
+	rs.refreshing = nil
+	rs.mu.Unlock()
+	close(call.done)
+	return call.token, call.err
+}
+
+// current returns the cached token without refreshing it
+func (rs *refreshingSource) current() Token {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.token
+}
+
+// clientKey is the context key of the Client a token is requested for
+type clientKey struct{}
+
+// withClient returns a copy of ctx carrying cli, the token sources of this package
+// perform their requests like the API-calls of cli (see requester)
+func withClient(ctx context.Context, cli *Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, cli)
+}
+
+// requester returns the Client to perform the requests of a token source with, which is
+// the Client the token is requested for. Its endpoints, retry policy and user agent are
+// kept if httpClient is set, only the requests are performed with httpClient instead.
+func requester(ctx context.Context, httpClient *http.Client) *Client {
+	cli, ok := ctx.Value(clientKey{}).(*Client)
+	if !ok {
+		cli = &Client{}
+	}
+	if httpClient == nil {
+		return cli
+	}
+	return &Client{
+		httpClient:   httpClient,
+		loginBaseURL: cli.loginBaseURL,
+		baseURL:      cli.baseURL,
+		resource:     cli.resource,
+		apiVersion:   cli.apiVersion,
+		userAgent:    cli.userAgent,
+		retry:        cli.retry,
+		v1Endpoint:   cli.v1Endpoint,
+	}
+}
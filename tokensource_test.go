@@ -0,0 +1,77 @@
+package drive_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+func TestRefreshingTokenSource(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	src := drive.RefreshingTokenSource(drive.TokenSourceFunc(func(ctx context.Context) (drive.Token, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return drive.Token{
+			TokenType:   "Bearer",
+			NotBefore:   time.Now().Add(-time.Minute),
+			ExpiresOn:   time.Now().Add(time.Hour),
+			AccessToken: "token",
+		}, nil
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := src.Token(context.Background())
+			if err != nil || token.AccessToken != "token" {
+				t.Errorf("unexpected token %v, error %v", token, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond) // let the callers pile up behind the first refresh
+	close(release)
+	wg.Wait()
+
+	if _, err := src.Token(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected a single refresh, got %d", n)
+	}
+}
+
+func TestNewClient_StaticTokenSource(t *testing.T) {
+	token := drive.Token{
+		TokenType:   "Bearer",
+		NotBefore:   time.Now().Add(-time.Minute),
+		ExpiresOn:   time.Now().Add(time.Hour),
+		AccessToken: "static",
+	}
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"id":"root-id"}`)
+	}))
+	defer srv.Close()
+
+	client, err := drive.NewClient(drive.StaticTokenSource(token), drive.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetDrive("drive-id").Item(""); err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "Bearer static" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+}
@@ -0,0 +1,160 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTreeWorkers is the number of files uploaded concurrently by UploadTree if no other has been set
+const DefaultTreeWorkers int = 4
+
+// TreeOptions configures UploadTree
+type TreeOptions struct {
+	Workers  int                     // number of files uploaded concurrently, defaults to DefaultTreeWorkers
+	Conflict ConflictBehavior        // what to do with changed files, defaults to ConflictReplace
+	Upload   UploadOptions           // options of every single upload, Conflict and LastModified are set by UploadTree
+	Progress func(result TreeResult) // called after every file, may be called concurrently
+}
+
+// TreeResult is the outcome of a single file of UploadTree
+type TreeResult struct {
+	LocalPath  string
+	RemotePath string
+	Size       int64
+	Skipped    bool  // the remote file has the same size and modification time already
+	Item       *Item // the uploaded item, nil if skipped or failed
+	Err        error
+}
+
+// UploadTree uploads the files of the local directory localDir to the folder at remotePath, which is
+// created if required. Remote folders are created for all local directories. Files are uploaded
+// by a pool of workers and their modification time is kept, files whose remote counterpart has
+// the same size and modification time are skipped. opts may be nil.
+//
+// The report contains a TreeResult for every file. The returned error is only set if the tree
+// could not be walked or a folder could not be created, failed uploads are reported in the results.
+func (drv *Drive) UploadTree(localDir, remotePath string, opts *TreeOptions) ([]TreeResult, error) {
+	return drv.UploadTreeContext(context.Background(), localDir, remotePath, opts)
+}
+
+// UploadTreeContext is like UploadTree but performs all API-calls with ctx.
+func (drv *Drive) UploadTreeContext(ctx context.Context, localDir, remotePath string, opts *TreeOptions) ([]TreeResult, error) {
+	if opts == nil {
+		opts = &TreeOptions{}
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultTreeWorkers
+	}
+	remotePath = strings.Trim(path.Clean("/"+remotePath), "/")
+	// the walk only creates folders whose parent exists already
+	if _, err := drv.MkdirAllContext(ctx, remotePath); err != nil {
+		return nil, fmt.Errorf("unable to create folder %v: %v", remotePath, err)
+	}
+
+	// folders are created in walk order, hence parents before their children. The remote children
+	// of every folder are listed once to tell unchanged files.
+	var results []TreeResult
+	remote := map[string]*Item{} // by remote path
+	err := filepath.Walk(localDir, func(localPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, localPath)
+		if err != nil {
+			return err
+		}
+		remoteItem := path.Join(remotePath, filepath.ToSlash(rel))
+		switch {
+		case info.IsDir():
+			children, err := drv.ensureFolder(ctx, remoteItem)
+			if err != nil {
+				return fmt.Errorf("unable to create folder %v: %v", remoteItem, err)
+			}
+			for _, child := range children {
+				remote[path.Join(remoteItem, child.Name)] = child
+			}
+		case info.Mode().IsRegular():
+			results = append(results, TreeResult{LocalPath: localPath, RemotePath: remoteItem, Size: info.Size()})
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return results, err
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				res := &results[idx]
+				drv.uploadTreeFile(ctx, res, remote[res.RemotePath], opts)
+				if opts.Progress != nil {
+					opts.Progress(*res)
+				}
+			}
+		}()
+	}
+	for idx := range results {
+		if ctx.Err() != nil {
+			results[idx].Err = ctx.Err()
+			continue
+		}
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+	return results, ctx.Err()
+}
+
+// uploadTreeFile uploads the file of res unless existing has the same size and modification time
+func (drv *Drive) uploadTreeFile(ctx context.Context, res *TreeResult, existing *Item, opts *TreeOptions) {
+	f, err := os.Open(res.LocalPath)
+	if err != nil {
+		res.Err = err
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		res.Err = err
+		return
+	}
+	res.Size = info.Size()
+	modTime := info.ModTime().Truncate(time.Second) // msgraph keeps whole seconds only
+	if existing != nil && existing.Size == res.Size && existing.FileSystemInfo.LastModifiedDateTime.Equal(modTime) {
+		res.Skipped = true
+		return
+	}
+
+	uploadOpts := opts.Upload
+	uploadOpts.Conflict = opts.Conflict
+	if uploadOpts.Conflict == ConflictDefault {
+		uploadOpts.Conflict = ConflictReplace
+	}
+	uploadOpts.LastModified = modTime
+	res.Item, res.Err = drv.UploadContext(ctx, res.RemotePath, f, res.Size, &uploadOpts)
+}
+
+// ensureFolder returns the children of the folder at path. The folder is created if it does not exist,
+// its parent has to exist already.
+func (drv *Drive) ensureFolder(ctx context.Context, folder string) ([]*Item, error) {
+	children, err := drv.ListChildrenContext(ctx, folder)
+	if errorCode(err) != "itemNotFound" {
+		return children, err
+	}
+	_, err = drv.MkdirContext(ctx, folder, ConflictFail)
+	if errorCode(err) == "nameAlreadyExists" { // created concurrently
+		return drv.ListChildrenContext(ctx, folder)
+	}
+	return nil, err
+}
@@ -0,0 +1,183 @@
+package drive_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+// fakeTree is an in-memory drive "drive-id" supporting getting and listing items, folder creation,
+// simple uploads and updating the modification time. The ID of an item is its path.
+type fakeTree struct {
+	mu      sync.Mutex
+	items   map[string]*drive.Item // by path, the root folder is ""
+	uploads int
+}
+
+func newFakeTree() *fakeTree {
+	root := &drive.Item{ID: "root"}
+	root.Folder = &struct {
+		ChildCount int `json:"childCount"`
+	}{}
+	return &fakeTree{items: map[string]*drive.Item{"": root}}
+}
+
+func (ft *fakeTree) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	p := strings.TrimPrefix(r.URL.Path, "/v1.0/drives/drive-id")
+	var suffix string
+	switch {
+	case p == "/items/root/children":
+		p, suffix = "", "children"
+	case strings.HasPrefix(p, "/items/root:/") && strings.HasSuffix(p, ":/children"):
+		p, suffix = strings.TrimSuffix(strings.TrimPrefix(p, "/items/root:/"), ":/children"), "children"
+	case strings.HasPrefix(p, "/root:/") && strings.HasSuffix(p, ":/content"):
+		p, suffix = strings.TrimSuffix(strings.TrimPrefix(p, "/root:/"), ":/content"), "content"
+	case strings.HasPrefix(p, "/root:/"):
+		p = strings.TrimPrefix(p, "/root:/")
+	case strings.HasPrefix(p, "/items/"):
+		p = strings.TrimPrefix(p, "/items/")
+	}
+	if p == "root" {
+		p = ""
+	}
+
+	switch {
+	case r.Method == "GET" && suffix == "children":
+		if _, ok := ft.items[p]; !ok {
+			ft.fail(w, http.StatusNotFound, "itemNotFound")
+			return
+		}
+		var children []*drive.Item
+		for itemPath, item := range ft.items {
+			if itemPath != "" && path.Dir("/"+itemPath) == "/"+p {
+				children = append(children, item)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"value": children})
+	case r.Method == "POST" && suffix == "children":
+		var in struct{ Name string }
+		json.NewDecoder(r.Body).Decode(&in)
+		itemPath := strings.TrimPrefix(path.Join(p, in.Name), "/")
+		if _, ok := ft.items[p]; !ok { // the parent has to exist
+			ft.fail(w, http.StatusNotFound, "itemNotFound")
+			return
+		}
+		if _, ok := ft.items[itemPath]; ok {
+			ft.fail(w, http.StatusConflict, "nameAlreadyExists")
+			return
+		}
+		item := &drive.Item{ID: itemPath, Name: in.Name}
+		item.Folder = &struct {
+			ChildCount int `json:"childCount"`
+		}{}
+		ft.items[itemPath] = item
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(item)
+	case r.Method == "PUT" && suffix == "content":
+		body, _ := ioutil.ReadAll(r.Body)
+		item := &drive.Item{ID: p, Name: path.Base(p), Size: int64(len(body))}
+		item.FileSystemInfo.LastModifiedDateTime = time.Now()
+		ft.items[p] = item
+		ft.uploads++
+		json.NewEncoder(w).Encode(item)
+	case r.Method == "GET" && suffix == "" && ft.items[p] != nil:
+		json.NewEncoder(w).Encode(ft.items[p])
+	case r.Method == "PATCH" && ft.items[p] != nil:
+		var in drive.Item
+		json.NewDecoder(r.Body).Decode(&in)
+		ft.items[p].FileSystemInfo.LastModifiedDateTime = in.FileSystemInfo.LastModifiedDateTime
+		json.NewEncoder(w).Encode(ft.items[p])
+	default:
+		ft.fail(w, http.StatusNotFound, "itemNotFound")
+	}
+}
+
+func (ft *fakeTree) fail(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"code": code}})
+}
+
+func TestDrive_UploadTree(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.txt": "a", "sub/b.txt": "bb", "sub/deeper/c.txt": "ccc"} {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Mkdir(filepath.Join(dir, "empty"), 0755)
+
+	ft := newFakeTree()
+	_, client := newFakeGraph(t, ft)
+	drv := client.GetDrive("drive-id")
+
+	results, err := drv.UploadTree(dir, "/backup", &drive.TreeOptions{Workers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || ft.uploads != 3 {
+		t.Fatalf("unexpected results %+v after %d uploads", results, ft.uploads)
+	}
+	for _, res := range results {
+		if res.Err != nil || res.Skipped || res.Item == nil {
+			t.Errorf("unexpected result %+v", res)
+		}
+	}
+	for _, folder := range []string{"backup", "backup/sub", "backup/sub/deeper", "backup/empty"} {
+		if item := ft.items[folder]; item == nil || !item.IsFolder() {
+			t.Errorf("folder %v has not been created", folder)
+		}
+	}
+
+	// unchanged files are skipped
+	ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("changed"), 0644)
+	results, err = drv.UploadTree(dir, "/backup", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skipped := 0
+	for _, res := range results {
+		if res.Skipped {
+			skipped++
+		} else if res.RemotePath != "backup/a.txt" {
+			t.Errorf("unexpected upload of %v", res.RemotePath)
+		}
+	}
+	if skipped != 2 || ft.uploads != 4 {
+		t.Errorf("expected 2 skipped files and 4 uploads, got %d and %d", skipped, ft.uploads)
+	}
+}
+
+func TestDrive_UploadTreeNested(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ft := newFakeTree()
+	_, client := newFakeGraph(t, ft)
+
+	// none of the folders of the target exists
+	results, err := client.GetDrive("drive-id").UploadTree(dir, "/backups/2026/10", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Err != nil || results[0].RemotePath != "backups/2026/10/a.txt" {
+		t.Errorf("unexpected results %+v", results)
+	}
+	for _, folder := range []string{"backups", "backups/2026", "backups/2026/10"} {
+		if item := ft.items[folder]; item == nil || !item.IsFolder() {
+			t.Errorf("folder %v has not been created", folder)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Put uploads the content of r, which has to be size bytes long, to the file at path and returns the
+// created item. Files larger than MaxSimpleUploadSize can not be uploaded with Put. The content is
+// verified against the hashes of the created item, a mismatch is returned as *HashMismatchError
+// whose Item is the created file.
+func (drv *Drive) Put(path string, r io.Reader, size int64, conflict ConflictBehavior) (*Item, error) {
+	return drv.PutContext(context.Background(), path, r, size, conflict)
+}
+
+// PutContext is like Put but performs the API-call with ctx.
+func (drv *Drive) PutContext(ctx context.Context, path string, r io.Reader, size int64, conflict ConflictBehavior) (*Item, error) {
+	if isRoot(path) {
+		return nil, fmt.Errorf("can not upload to the root folder itself")
+	}
+	return drv.put(ctx, drv.itemSource(path), r, size, conflict)
+}
+
+// PutByID is like Put but uploads to the file called name in the folder with parentID.
+func (drv *Drive) PutByID(parentID, name string, r io.Reader, size int64, conflict ConflictBehavior) (*Item, error) {
+	return drv.PutByIDContext(context.Background(), parentID, name, r, size, conflict)
+}
+
+// PutByIDContext is like PutByID but performs the API-call with ctx.
+func (drv *Drive) PutByIDContext(ctx context.Context, parentID, name string, r io.Reader, size int64, conflict ConflictBehavior) (*Item, error) {
+	source, err := drv.uploadSource(parentID, name)
+	if err != nil {
+		return nil, err
+	}
+	return drv.put(ctx, source, r, size, conflict)
+}
+
+// uploadSource returns the API-call addressing the file called name in the folder with parentID
+func (drv *Drive) uploadSource(parentID, name string) (string, error) {
+	if parentID == "" {
+		return "", fmt.Errorf("item ID is empty")
+	}
+	if strings.Trim(name, "/") == "" {
+		return "", fmt.Errorf("file name is empty")
+	}
+	return drv.childSource(parentID, name), nil
+}
+
+// put uploads the content of r to the file of the API-call source, see Put
+func (drv *Drive) put(ctx context.Context, source string, r io.Reader, size int64, conflict ConflictBehavior) (*Item, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid size %v", size)
+	}
+	if size > MaxSimpleUploadSize {
+		return nil, fmt.Errorf("size %v exceeds the limit of %v bytes of a simple upload", size, MaxSimpleUploadSize)
+	}
+
+	// the content is buffered, hence the request can be retried
+	content := make([]byte, size)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, fmt.Errorf("unable to read %v bytes of content: %v", size, err)
+	}
+
+	reqURL, err := drv.Client.apiURL(actionSource(source, "content"), conflict.params())
+	if err != nil {
+		return nil, err
+	}
+	req, err := drv.Client.newRequest(ctx, "PUT", reqURL, bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/octet-stream")
+
+	marsh := &Item{}
+	if err := drv.Client.performRequest(req, marsh); err != nil {
+		return nil, err
+	}
+	if err := marsh.verify(bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+	return marsh, nil
+}
+
+// verify compares the content r with the hashes of the item, if it is a file. A mismatch is
+// returned as *HashMismatchError referring to item.
+func (item *Item) verify(r io.Reader) error {
+	if item.File == nil {
+		return nil
+	}
+	err := item.File.Hashes.Verify(r)
+	if he, ok := err.(*HashMismatchError); ok {
+		he.Item = item
+	}
+	return err
+}
+
+// UploadSession is a resumable upload of a large file. The content is uploaded in chunks, an
+// interrupted upload continues at the first byte the server is still missing.
+// See https://docs.microsoft.com/en-us/graph/api/driveitem-createuploadsession
+//
+// UploadURL is all that is needed to resume the upload after a restart, see ResumeUploadSession.
+type UploadSession struct {
+	UploadURL          string    `json:"uploadUrl"`          // pre-authenticated URL the chunks are sent to
+	ExpiresOn          time.Time `json:"expirationDateTime"` // time when the session expires if no chunk is received
+	NextExpectedRanges []string  `json:"nextExpectedRanges"` // byte ranges the server is still missing, e.g. "1048576-"
+
+	client *Client
+}
+
+// UploadOptions configures an upload, see Drive.Upload and UploadSession.Upload
+type UploadOptions struct {
+	Conflict  ConflictBehavior           // what to do if the file exists already
+	ChunkSize int64                      // size of a chunk, has to be a multiple of UploadChunkMultiple. Defaults to DefaultUploadChunkSize
+	Progress  func(uploaded, size int64) // called after every chunk, may be nil
+	NoVerify  bool                       // do not verify the content against the hashes of the created item, see Hashes.Verify
+
+	// ChunkTimeout is the time limit of sending a chunk and receiving the response, zero means no limit. Chunks
+	// are not bound to the time limit of the Client (see WithTimeout) as they take long on slow connections.
+	ChunkTimeout time.Duration
+
+	LastModified time.Time // set as FileSystemInfo.LastModifiedDateTime of the file by Drive.Upload, zero keeps the upload time
+}
+
+// chunkSize returns the size of a chunk
+func (o *UploadOptions) chunkSize() (int64, error) {
+	if o == nil || o.ChunkSize == 0 {
+		return DefaultUploadChunkSize, nil
+	}
+	if o.ChunkSize < 0 || o.ChunkSize%UploadChunkMultiple != 0 {
+		return 0, fmt.Errorf("chunk size %v is not a multiple of %v", o.ChunkSize, UploadChunkMultiple)
+	}
+	return o.ChunkSize, nil
+}
+
+// chunkTimeout returns the time limit of a chunk, zero if there is none
+func (o *UploadOptions) chunkTimeout() time.Duration {
+	if o == nil {
+		return 0
+	}
+	return o.ChunkTimeout
+}
+
+// progress reports the progress if a callback has been set
+func (o *UploadOptions) progress(uploaded, size int64) {
+	if o != nil && o.Progress != nil {
+		o.Progress(uploaded, size)
+	}
+}
+
+// Upload uploads size bytes of r to the file at path and returns the created item. Files up to
+// MaxSimpleUploadSize are uploaded with Put, larger ones with an upload session which is deleted
+// if the upload fails. A failed verification is returned as *HashMismatchError whose Item is the
+// created file, see HashMismatchError. opts may be nil.
+func (drv *Drive) Upload(path string, r io.ReaderAt, size int64, opts *UploadOptions) (*Item, error) {
+	return drv.UploadContext(context.Background(), path, r, size, opts)
+}
+
+// UploadContext is like Upload but performs the API-calls with ctx.
+func (drv *Drive) UploadContext(ctx context.Context, path string, r io.ReaderAt, size int64, opts *UploadOptions) (*Item, error) {
+	if isRoot(path) {
+		return nil, fmt.Errorf("can not upload to the root folder itself")
+	}
+	return drv.upload(ctx, drv.itemSource(path), r, size, opts)
+}
+
+// UploadByID is like Upload but uploads to the file called name in the folder with parentID.
+func (drv *Drive) UploadByID(parentID, name string, r io.ReaderAt, size int64, opts *UploadOptions) (*Item, error) {
+	return drv.UploadByIDContext(context.Background(), parentID, name, r, size, opts)
+}
+
+// UploadByIDContext is like UploadByID but performs the API-calls with ctx.
+func (drv *Drive) UploadByIDContext(ctx context.Context, parentID, name string, r io.ReaderAt, size int64, opts *UploadOptions) (*Item, error) {
+	source, err := drv.uploadSource(parentID, name)
+	if err != nil {
+		return nil, err
+	}
+	return drv.upload(ctx, source, r, size, opts)
+}
+
+// upload uploads size bytes of r to the file of the API-call source, see Upload
+func (drv *Drive) upload(ctx context.Context, source string, r io.ReaderAt, size int64, opts *UploadOptions) (*Item, error) {
+	if _, err := opts.chunkSize(); err != nil {
+		return nil, err
+	}
+	var conflict ConflictBehavior
+	var lastModified time.Time
+	if opts != nil {
+		conflict, lastModified = opts.Conflict, opts.LastModified
+	}
+	var item *Item
+	if size <= MaxSimpleUploadSize {
+		var err error
+		item, err = drv.put(ctx, source, io.NewSectionReader(r, 0, size), size, conflict)
+		if err != nil {
+			return nil, err
+		}
+		opts.progress(size, size)
+	} else {
+		us, err := drv.createUploadSession(ctx, source, conflict)
+		if err != nil {
+			return nil, err
+		}
+		item, err = us.Upload(ctx, r, size, opts)
+		if err != nil {
+			// the caller's ctx might be done already
+			cancelCtx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+			defer cancel()
+			us.Cancel(cancelCtx)
+			return nil, err
+		}
+	}
+	if lastModified.IsZero() {
+		return item, nil
+	}
+	return drv.setLastModified(ctx, item.ID, lastModified)
+}
+
+// setLastModified sets FileSystemInfo.LastModifiedDateTime of the item with id and returns the updated item
+func (drv *Drive) setLastModified(ctx context.Context, id string, lastModified time.Time) (*Item, error) {
+	in := map[string]interface{}{
+		"fileSystemInfo": map[string]interface{}{"lastModifiedDateTime": lastModified.UTC()},
+	}
+	return drv.update(ctx, drv.idSource(id), in, ConflictDefault)
+}
+
+// CreateUploadSession starts an upload session for the file at path.
+func (drv *Drive) CreateUploadSession(path string, conflict ConflictBehavior) (*UploadSession, error) {
+	return drv.CreateUploadSessionContext(context.Background(), path, conflict)
+}
+
+// CreateUploadSessionContext is like CreateUploadSession but performs the API-call with ctx.
+func (drv *Drive) CreateUploadSessionContext(ctx context.Context, path string, conflict ConflictBehavior) (*UploadSession, error) {
+	if isRoot(path) {
+		return nil, fmt.Errorf("can not upload to the root folder itself")
+	}
+	return drv.createUploadSession(ctx, drv.itemSource(path), conflict)
+}
+
+// CreateUploadSessionByID is like CreateUploadSession but starts an upload session for the file called
+// name in the folder with parentID.
+func (drv *Drive) CreateUploadSessionByID(parentID, name string, conflict ConflictBehavior) (*UploadSession, error) {
+	return drv.CreateUploadSessionByIDContext(context.Background(), parentID, name, conflict)
+}
+
+// CreateUploadSessionByIDContext is like CreateUploadSessionByID but performs the API-call with ctx.
+func (drv *Drive) CreateUploadSessionByIDContext(ctx context.Context, parentID, name string, conflict ConflictBehavior) (*UploadSession, error) {
+	source, err := drv.uploadSource(parentID, name)
+	if err != nil {
+		return nil, err
+	}
+	return drv.createUploadSession(ctx, source, conflict)
+}
+
+// createUploadSession starts an upload session for the file of the API-call source
+func (drv *Drive) createUploadSession(ctx context.Context, source string, conflict ConflictBehavior) (*UploadSession, error) {
+	in := map[string]interface{}{"item": map[string]interface{}{}}
+	if conflict != ConflictDefault {
+		in["item"] = map[string]interface{}{"@microsoft.graph.conflictBehavior": conflict}
+	}
+	us := &UploadSession{client: drv.Client}
+	err := drv.Client.makeAPICall(ctx, "POST", actionSource(source, "createUploadSession"), nil, in, us)
+	if err != nil {
+		return nil, err
+	}
+	return us, nil
+}
+
+// ResumeUploadSession continues the upload session of uploadURL, e.g. after a restart. The ranges
+// the server is still missing are queried immediately.
+func (drv *Drive) ResumeUploadSession(uploadURL string) (*UploadSession, error) {
+	return drv.ResumeUploadSessionContext(context.Background(), uploadURL)
+}
+
+// ResumeUploadSessionContext is like ResumeUploadSession but performs the API-call with ctx.
+func (drv *Drive) ResumeUploadSessionContext(ctx context.Context, uploadURL string) (*UploadSession, error) {
+	us := &UploadSession{UploadURL: uploadURL, client: drv.Client}
+	if err := us.Status(ctx); err != nil {
+		return nil, err
+	}
+	return us, nil
+}
+
+// Status queries the ranges the server is still missing and updates NextExpectedRanges.
+func (us *UploadSession) Status(ctx context.Context) error {
+	// the upload URL is pre-authenticated, an Authorization header must not be sent
+	req, err := http.NewRequestWithContext(ctx, "GET", us.UploadURL, nil)
+	if err != nil {
+		return fmt.Errorf("HTTP request error: %v", err)
+	}
+	us.NextExpectedRanges = nil
+	return us.client.performRequest(req, us)
+}
+
+// Cancel deletes the upload session, the chunks uploaded so far are discarded.
+func (us *UploadSession) Cancel(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", us.UploadURL, nil)
+	if err != nil {
+		return fmt.Errorf("HTTP request error: %v", err)
+	}
+	return us.client.performRequest(req, nil)
+}
+
+// Upload uploads the content of r, which has to be size bytes long, starting at the first byte the
+// server is still missing. It returns the created item once the last chunk has been received.
+// Unless opts.NoVerify is set, the whole content is read again and verified against the hashes of
+// the created item, a mismatch is returned as *HashMismatchError whose Item is the created file.
+// The session is kept if the upload fails, hence it can be resumed. opts may be nil, its Conflict
+// is ignored as it has been fixed when the session was created.
+func (us *UploadSession) Upload(ctx context.Context, r io.ReaderAt, size int64, opts *UploadOptions) (*Item, error) {
+	chunkSize, err := opts.chunkSize()
+	if err != nil {
+		return nil, err
+	}
+	offset, err := us.nextOffset()
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, chunkSize)
+	for offset < size {
+		chunk := buf
+		if size-offset < chunkSize {
+			chunk = buf[:size-offset]
+		}
+		if n, err := r.ReadAt(chunk, offset); err != nil && !(err == io.EOF && n == len(chunk)) {
+			return nil, fmt.Errorf("unable to read content at offset %v: %v", offset, err)
+		}
+
+		item, err := us.putChunk(ctx, chunk, offset, size, opts.chunkTimeout())
+		if re, ok := err.(*ReqError); ok && re.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			// the server got a different part than expected, e.g. the response of the previous chunk got lost
+			err = us.Status(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if item != nil {
+			opts.progress(size, size)
+			if opts == nil || !opts.NoVerify {
+				if err := item.verify(io.NewSectionReader(r, 0, size)); err != nil {
+					return nil, err
+				}
+			}
+			return item, nil
+		}
+		if len(us.NextExpectedRanges) == 0 {
+			break
+		}
+		if offset, err = us.nextOffset(); err != nil {
+			return nil, err
+		}
+		opts.progress(offset, size)
+	}
+	return nil, fmt.Errorf("upload session expects no more data but did not complete")
+}
+
+// putChunk uploads chunk starting at offset within timeout, if it is not zero. It returns the created
+// item if this was the last missing chunk, otherwise NextExpectedRanges is updated.
+func (us *UploadSession) putChunk(ctx context.Context, chunk []byte, offset, size int64, timeout time.Duration) (*Item, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", us.UploadURL, bytes.NewReader(chunk))
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request error: %v", err)
+	}
+	req.Header.Add("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(chunk))-1, size))
+
+	resp, err := us.client.streaming().do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP response read error: %v of http.Request: %v", err, req.URL)
+	}
+
+	if resp.StatusCode == http.StatusAccepted { // more chunks are expected
+		us.NextExpectedRanges = nil
+		return nil, json.Unmarshal(body, us)
+	}
+	item := &Item{}
+	if err := json.Unmarshal(body, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// nextOffset returns the first byte the server is still missing, 0 if the session did not report any range yet
+func (us *UploadSession) nextOffset() (int64, error) {
+	if len(us.NextExpectedRanges) == 0 {
+		return 0, nil
+	}
+	start := strings.SplitN(us.NextExpectedRanges[0], "-", 2)[0]
+	offset, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed expected range %q", us.NextExpectedRanges[0])
+	}
+	return offset, nil
+}
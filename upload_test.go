@@ -0,0 +1,240 @@
+package drive_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	drive "github.com/iochen/msgraph-drive"
+)
+
+func TestDrive_Put(t *testing.T) {
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method != "PUT" || r.URL.Path != "/v1.0/drives/drive-id/root:/folder/file.txt:/content" ||
+			r.URL.Query().Get("@microsoft.graph.conflictBehavior") != "rename" || len(body) != 7 {
+			t.Errorf("unexpected request %v %v: %q", r.Method, r.URL, body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		// the hashes of "content"
+		fmt.Fprintf(w, `{"id":"file-id","name":"file 1.txt","size":%d,"file":{"mimeType":"text/plain",`+
+			`"hashes":{"sha1Hash":"040F06FD774092478D450774F5BA30C5DA78ACC8"}}}`, len(body))
+	}))
+	drv := client.GetDrive("drive-id")
+
+	item, err := drv.Put("/folder/file.txt", strings.NewReader("content"), 7, drive.ConflictRename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != "file-id" || item.Size != 7 {
+		t.Errorf("unexpected item %#v", item)
+	}
+
+	if _, err := drv.Put("/folder/file.txt", strings.NewReader("altered"), 7, drive.ConflictRename); err != nil {
+		if he, ok := err.(*drive.HashMismatchError); !ok {
+			t.Errorf("expected hash mismatch, got %v", err)
+		} else if he.Item == nil || he.Item.ID != "file-id" {
+			t.Errorf("hash mismatch does not refer to the created item: %#v", he.Item)
+		}
+	} else {
+		t.Error("altered content has not been detected")
+	}
+
+	if _, err := drv.Put("/large", strings.NewReader(""), drive.MaxSimpleUploadSize+1, drive.ConflictDefault); err == nil {
+		t.Error("simple upload of a large file did not fail")
+	}
+	if _, err := drv.Put("/short", strings.NewReader("abc"), 4, drive.ConflictDefault); err == nil {
+		t.Error("upload of too short content did not fail")
+	}
+	if _, err := drv.Put("/negative", strings.NewReader(""), -1, drive.ConflictDefault); err == nil || !strings.Contains(err.Error(), "invalid size") {
+		t.Errorf("expected invalid size, got %v", err)
+	}
+}
+
+// fakeUploadSession serves an upload session at /upload/session and stores the received content
+type fakeUploadSession struct {
+	t        *testing.T
+	content  []byte
+	received int64 // all bytes before received have been uploaded
+	puts     int
+	deleted  bool
+	delay    time.Duration // time to wait before answering a chunk
+}
+
+func (fs *fakeUploadSession) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == "POST" && (r.URL.Path == "/v1.0/drives/drive-id/root:/backup.tar:/createUploadSession" ||
+		r.URL.Path == "/v1.0/drives/drive-id/items/folder-id:/backup.tar:/createUploadSession"):
+		fmt.Fprintf(w, `{"uploadUrl":"http://%s/upload/session","expirationDateTime":"2030-01-01T00:00:00Z","nextExpectedRanges":["0-"]}`, r.Host)
+	case r.Method == "GET" && r.URL.Path == "/upload/session":
+		fmt.Fprintf(w, `{"expirationDateTime":"2030-01-01T00:00:00Z","nextExpectedRanges":["%d-"]}`, fs.received)
+	case r.Method == "DELETE" && r.URL.Path == "/upload/session":
+		fs.deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "PUT" && r.URL.Path == "/upload/session":
+		time.Sleep(fs.delay)
+		fs.puts++
+		if r.Header.Get("Authorization") != "" {
+			fs.t.Error("Authorization header sent to the upload URL")
+		}
+		var start, end, size int64
+		fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &size)
+		body, _ := ioutil.ReadAll(r.Body)
+		if start != fs.received || int64(len(body)) != end-start+1 {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		fs.content = append(fs.content, body...)
+		fs.received = end + 1
+		if fs.received < size {
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprintf(w, `{"nextExpectedRanges":["%d-"]}`, fs.received)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":"backup-id","size":%d}`, size)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// failingReaderAt fails to read beyond limit
+type failingReaderAt struct {
+	r     io.ReaderAt
+	limit int64
+}
+
+func (f failingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) > f.limit {
+		return 0, errors.New("disk on fire")
+	}
+	return f.r.ReadAt(p, off)
+}
+
+func TestUploadSession_Resume(t *testing.T) {
+	fs := &fakeUploadSession{t: t}
+	_, client := newFakeGraph(t, fs)
+	drv := client.GetDrive("drive-id")
+
+	content := bytes.Repeat([]byte("0123456789"), int(drive.UploadChunkMultiple)/4) // 2.5 chunks
+	opts := &drive.UploadOptions{ChunkSize: drive.UploadChunkMultiple}
+	us, err := drv.CreateUploadSession("/backup.tar", drive.ConflictReplace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := failingReaderAt{r: bytes.NewReader(content), limit: drive.UploadChunkMultiple}
+	if _, err := us.Upload(context.Background(), r, int64(len(content)), opts); err == nil {
+		t.Fatal("upload did not fail")
+	}
+
+	// resume in a new session as after a restart
+	us, err = drv.ResumeUploadSession(us.UploadURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var progress []int64
+	opts.Progress = func(uploaded, size int64) {
+		progress = append(progress, uploaded)
+	}
+	item, err := us.Upload(context.Background(), bytes.NewReader(content), int64(len(content)), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != "backup-id" || !bytes.Equal(fs.content, content) {
+		t.Errorf("unexpected item %#v or content of %d bytes", item, len(fs.content))
+	}
+	if fs.puts != 3 || len(progress) != 2 || progress[1] != int64(len(content)) {
+		t.Errorf("unexpected %d chunks, progress %v", fs.puts, progress)
+	}
+}
+
+func TestDrive_UploadCancel(t *testing.T) {
+	fs := &fakeUploadSession{t: t}
+	_, client := newFakeGraph(t, fs)
+	drv := client.GetDrive("drive-id")
+
+	size := drive.MaxSimpleUploadSize + 1
+	r := failingReaderAt{r: bytes.NewReader(make([]byte, size)), limit: drive.DefaultUploadChunkSize}
+	if _, err := drv.Upload("/backup.tar", r, size, nil); err == nil {
+		t.Fatal("upload did not fail")
+	}
+	if !fs.deleted {
+		t.Error("the session of the failed upload was not deleted")
+	}
+}
+
+func TestDrive_UploadByID(t *testing.T) {
+	fs := &fakeUploadSession{t: t}
+	var puts int
+	_, client := newFakeGraph(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" && r.URL.Path == "/v1.0/drives/drive-id/items/folder-id:/small.txt:/content" {
+			puts++
+			fmt.Fprint(w, `{"id":"small-id","size":7}`)
+			return
+		}
+		fs.ServeHTTP(w, r)
+	}))
+	drv := client.GetDrive("drive-id")
+
+	item, err := drv.PutByID("folder-id", "small.txt", strings.NewReader("content"), 7, drive.ConflictDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != "small-id" || puts != 1 {
+		t.Errorf("unexpected item %#v", item)
+	}
+
+	content := bytes.Repeat([]byte("0123456789"), int(drive.MaxSimpleUploadSize)/8)
+	item, err = drv.UploadByID("folder-id", "backup.tar", bytes.NewReader(content), int64(len(content)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != "backup-id" || !bytes.Equal(fs.content, content) {
+		t.Errorf("unexpected item %#v or content of %d bytes", item, len(fs.content))
+	}
+
+	if _, err := drv.PutByID("", "small.txt", strings.NewReader("content"), 7, drive.ConflictDefault); err == nil {
+		t.Error("upload without parent ID did not fail")
+	}
+	if _, err := drv.PutByID("folder-id", "", strings.NewReader("content"), 7, drive.ConflictDefault); err == nil {
+		t.Error("upload without name did not fail")
+	}
+}
+
+func TestUploadSession_ChunkTimeout(t *testing.T) {
+	// a chunk taking longer than the time limit of the Client still succeeds
+	fs := &fakeUploadSession{t: t, delay: 100 * time.Millisecond}
+	_, client := newFakeGraph(t, fs, drive.WithTimeout(50*time.Millisecond))
+	drv := client.GetDrive("drive-id")
+
+	content := bytes.Repeat([]byte("0123456789"), int(drive.UploadChunkMultiple)/10)
+	us, err := drv.CreateUploadSession("/backup.tar", drive.ConflictReplace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := us.Upload(context.Background(), bytes.NewReader(content), int64(len(content)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != "backup-id" {
+		t.Errorf("unexpected item %#v", item)
+	}
+
+	// unless it exceeds the time limit of a chunk
+	fs.received, fs.content = 0, nil
+	us, err = drv.CreateUploadSession("/backup.tar", drive.ConflictReplace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &drive.UploadOptions{ChunkTimeout: 10 * time.Millisecond}
+	if _, err := us.Upload(context.Background(), bytes.NewReader(content), int64(len(content)), opts); err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("expected chunk exceeding ChunkTimeout to fail, got %v", err)
+	}
+}